@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// baselineContextLines is the number of source lines either side of an issue
+// that are included in its fingerprint.
+const baselineContextLines = 1
+
+// baselineEntry is a single issue recorded in a baseline file. Only the
+// fingerprint is used for matching, the remaining fields are informational.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Linter      string `json:"linter"`
+	Path        string `json:"path"`
+	Message     string `json:"message"`
+}
+
+type baselineFile struct {
+	Version int              `json:"version"`
+	Issues  []*baselineEntry `json:"issues"`
+}
+
+// baseline is the set of issues recorded in a baseline file.
+type baseline struct {
+	entries map[string][]*baselineEntry
+}
+
+func loadBaseline(path string) (*baseline, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() // nolint: errcheck
+	file := &baselineFile{}
+	if err := json.NewDecoder(r).Decode(file); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	b := &baseline{entries: map[string][]*baselineEntry{}}
+	for _, entry := range file.Issues {
+		b.entries[entry.Fingerprint] = append(b.entries[entry.Fingerprint], entry)
+	}
+	return b, nil
+}
+
+// baselineMatcher matches issues from a single run against a baseline. Each
+// baseline entry suppresses at most one issue.
+type baselineMatcher struct {
+	lock      sync.Mutex
+	remaining map[string][]*baselineEntry
+}
+
+func (b *baseline) newMatcher() *baselineMatcher {
+	remaining := make(map[string][]*baselineEntry, len(b.entries))
+	for fingerprint, entries := range b.entries {
+		remaining[fingerprint] = entries
+	}
+	return &baselineMatcher{remaining: remaining}
+}
+
+// Suppresses returns true if an issue with the given fingerprint is recorded
+// in the baseline and has not already been matched.
+func (b *baselineMatcher) Suppresses(fingerprint string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entries := b.remaining[fingerprint]
+	if len(entries) == 0 {
+		return false
+	}
+	b.remaining[fingerprint] = entries[1:]
+	return true
+}
+
+// Unmatched returns the baseline entries that did not match any issue, for
+// files in the linted paths. Each path is a package directory or a file;
+// entries for files that were not linted can not have matched.
+func (b *baselineMatcher) Unmatched(paths []string) []*baselineEntry {
+	linted := map[string]bool{}
+	for _, path := range paths {
+		linted[filepath.Clean(path)] = true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	out := []*baselineEntry{}
+	for _, entries := range b.remaining {
+		for _, entry := range entries {
+			if linted[filepath.Dir(entry.Path)] || linted[filepath.Clean(entry.Path)] {
+				out = append(out, entry)
+			}
+		}
+	}
+	sortBaselineEntries(out)
+	return out
+}
+
+func sortBaselineEntries(entries []*baselineEntry) {
+	sort.Sort(baselineEntries(entries))
+}
+
+type baselineEntries []*baselineEntry
+
+func (b baselineEntries) Len() int      { return len(b) }
+func (b baselineEntries) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b baselineEntries) Less(i, j int) bool {
+	l, r := b[i], b[j]
+	if l.Path != r.Path {
+		return l.Path < r.Path
+	}
+	if l.Linter != r.Linter {
+		return l.Linter < r.Linter
+	}
+	if l.Message != r.Message {
+		return l.Message < r.Message
+	}
+	return l.Fingerprint < r.Fingerprint
+}
+
+func writeBaseline(path string, entries []*baselineEntry) error {
+	sortBaselineEntries(entries)
+	d, err := json.MarshalIndent(&baselineFile{Version: 1, Issues: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(d, '\n'), 0644)
+}
+
+var (
+	baselineNumberRegex     = regexp.MustCompile(`\d+`)
+	baselineWhitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// normaliseBaselineMessage removes details from a message that are likely to
+// change when unrelated code changes, such as line numbers.
+func normaliseBaselineMessage(message string) string {
+	message = baselineNumberRegex.ReplaceAllString(message, "N")
+	return strings.TrimSpace(baselineWhitespaceRegex.ReplaceAllString(message, " "))
+}
+
+// baselineFingerprinter computes line number insensitive fingerprints for
+// issues, caching the source of each file it reads.
+type baselineFingerprinter struct {
+	lock    sync.Mutex
+	sources map[string][]string
+}
+
+func newBaselineFingerprinter() *baselineFingerprinter {
+	return &baselineFingerprinter{sources: map[string][]string{}}
+}
+
+func (f *baselineFingerprinter) lines(path string) []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	lines, ok := f.sources[path]
+	if !ok {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			debug("baseline: failed to read %s: %s", path, err)
+		}
+		lines = strings.Split(string(source), "\n")
+		f.sources[path] = lines
+	}
+	return lines
+}
+
+// Fingerprint an issue by its path, linter, normalised message and the source
+// surrounding it.
+func (f *baselineFingerprinter) Fingerprint(issue *Issue) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", issue.Path, issue.Linter, normaliseBaselineMessage(issue.Message))
+	lines := f.lines(issue.Path)
+	for i := issue.Line - 1 - baselineContextLines; i <= issue.Line-1+baselineContextLines; i++ {
+		if i >= 0 && i < len(lines) {
+			fmt.Fprintf(h, "%s\x00", strings.TrimSpace(lines[i]))
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:32]
+}
+
+func newBaselineEntry(fingerprint string, issue *Issue) *baselineEntry {
+	return &baselineEntry{
+		Fingerprint: fingerprint,
+		Linter:      issue.Linter,
+		Path:        issue.Path,
+		Message:     issue.Message,
+	}
+}
+
+// filterIssuesViaBaseline records issues to a new baseline file if writePath is
+// set, and suppresses issues found in existing if it is not nil. Entries in
+// existing for files in paths that no longer occur are reported.
+func filterIssuesViaBaseline(existing *baseline, writePath string, paths []string, issues chan *Issue) chan *Issue {
+	out := make(chan *Issue, 1000000)
+	fingerprinter := newBaselineFingerprinter()
+	var matcher *baselineMatcher
+	if existing != nil {
+		matcher = existing.newMatcher()
+	}
+	go func() {
+		entries := []*baselineEntry{}
+		for issue := range issues {
+			fingerprint := fingerprinter.Fingerprint(issue)
+			if writePath != "" {
+				entries = append(entries, newBaselineEntry(fingerprint, issue))
+			}
+			if matcher != nil && matcher.Suppresses(fingerprint) {
+				debug("baseline: suppressed issue %s", issue)
+				continue
+			}
+			out <- issue
+		}
+
+		if writePath != "" {
+			if err := writeBaseline(writePath, entries); err != nil {
+				warning("failed to write baseline %s: %s", writePath, err)
+			}
+		}
+		if matcher != nil {
+			for _, entry := range matcher.Unmatched(paths) {
+				warning("baseline entry no longer occurs: %s: %s (%s)", entry.Path, entry.Message, entry.Linter)
+			}
+		}
+		close(out)
+	}()
+	return out
+}
+
+func maybeFilterIssuesViaBaseline(paths []string, issues chan *Issue) chan *Issue {
+	if config.baseline == nil && config.WriteBaseline == "" {
+		return issues
+	}
+	return filterIssuesViaBaseline(config.baseline, config.WriteBaseline, paths, issues)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineFingerprintIgnoresLineNumbers(t *testing.T) {
+	_, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile("a.go", []byte("package foo\n\nfunc a() {\n\tx := 1\n}\n"), 0644))
+	fingerprinter := newBaselineFingerprinter()
+	issue := &Issue{Linter: "vet", Path: "a.go", Line: 4, Message: "x declared at line 4"}
+	original := fingerprinter.Fingerprint(issue)
+
+	require.NoError(t, ioutil.WriteFile("a.go", []byte("package foo\n\n\n\nfunc a() {\n\tx := 1\n}\n"), 0644))
+	fingerprinter = newBaselineFingerprinter()
+	moved := &Issue{Linter: "vet", Path: "a.go", Line: 6, Message: "x declared at line 6"}
+	assert.Equal(t, original, fingerprinter.Fingerprint(moved))
+
+	moved.Linter = "golint"
+	assert.NotEqual(t, original, fingerprinter.Fingerprint(moved))
+}
+
+func TestFilterIssuesViaBaseline(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile("a.go", []byte("package foo\nvar x = 1\nvar y = 2\n"), 0644))
+	input := []*Issue{
+		{Linter: "vet", Path: "a.go", Line: 2, Message: "bad x"},
+		{Linter: "vet", Path: "a.go", Line: 3, Message: "bad y"},
+	}
+	filter := func(existing *baseline, writePath string, issues ...*Issue) []*Issue {
+		in := make(chan *Issue, len(issues))
+		for _, issue := range issues {
+			in <- issue
+		}
+		close(in)
+		out := []*Issue{}
+		for issue := range filterIssuesViaBaseline(existing, writePath, []string{"."}, in) {
+			out = append(out, issue)
+		}
+		return out
+	}
+
+	path := filepath.Join(tmpdir, "baseline.json")
+	assert.Equal(t, input, filter(nil, path, input...))
+
+	existing, err := loadBaseline(path)
+	require.NoError(t, err)
+	added := &Issue{Linter: "vet", Path: "a.go", Line: 1, Message: "bad package"}
+	assert.Equal(t, []*Issue{added}, filter(existing, "", input[0], added))
+
+	// Each run matches against the full baseline.
+	assert.Equal(t, []*Issue{}, filter(existing, "", input...))
+
+	matcher := existing.newMatcher()
+	assert.True(t, matcher.Suppresses(newBaselineFingerprinter().Fingerprint(input[0])))
+	unmatched := matcher.Unmatched([]string{"."})
+	require.Len(t, unmatched, 1)
+	assert.Equal(t, "bad y", unmatched[0].Message)
+}
+
+func TestBaselineUnmatchedOnlyReportsLintedPaths(t *testing.T) {
+	entries := []*baselineEntry{
+		{Fingerprint: "1", Path: "a.go"},
+		{Fingerprint: "2", Path: filepath.Join("pkg", "b.go")},
+		{Fingerprint: "3", Path: filepath.Join("pkg", "sub", "c.go")},
+		{Fingerprint: "4", Path: filepath.Join("other", "d.go")},
+	}
+	existing := &baseline{entries: map[string][]*baselineEntry{}}
+	for _, entry := range entries {
+		existing.entries[entry.Fingerprint] = append(existing.entries[entry.Fingerprint], entry)
+	}
+
+	assert.Equal(t, entries[1:2], existing.newMatcher().Unmatched([]string{"./pkg"}))
+	assert.Equal(t, []*baselineEntry{entries[0], entries[3]}, existing.newMatcher().Unmatched([]string{".", "other/d.go"}))
+}
+
+func TestNormaliseBaselineMessage(t *testing.T) {
+	assert.Equal(t, "cyclomatic complexity N of function foo() is high (> N)",
+		normaliseBaselineMessage(" cyclomatic  complexity 14 of function foo() is high (> 10)"))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// buildContext is a combination of GOOS, GOARCH and build tags that linters
+// are run under.
+type buildContext struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+	// True if the context is an entry of the build matrix, rather than the
+	// default context of a run without one.
+	matrix bool
+}
+
+// String returns the context in the form accepted by --matrix.
+func (b *buildContext) String() string {
+	out := b.GOOS + "/" + b.GOARCH
+	if len(b.Tags) > 0 {
+		out += ":" + strings.Join(b.Tags, ",")
+	}
+	return out
+}
+
+// env returns the environment variables that select the context's GOOS and
+// GOARCH.
+func (b *buildContext) env() []string {
+	if !b.matrix {
+		return nil
+	}
+	return []string{"GOOS=" + b.GOOS, "GOARCH=" + b.GOARCH}
+}
+
+// splitBuildTags splits a list of build tags, each of which may itself be
+// a comma or space separated list.
+func splitBuildTags(tags []string) []string {
+	out := []string{}
+	for _, tag := range tags {
+		out = append(out, strings.FieldsFunc(tag, func(r rune) bool {
+			return r == ',' || r == ' '
+		})...)
+	}
+	return out
+}
+
+// defaultGoEnv returns the value of a Go environment variable, or fallback
+// if it is not set.
+func defaultGoEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseBuildContext parses a build matrix entry of the form
+// "[GOOS/GOARCH][:tag,...]". GOOS and GOARCH default to those of the host.
+// tags are added to the tags of the entry.
+func parseBuildContext(entry string, tags []string) (*buildContext, error) {
+	platform, entryTags := entry, ""
+	if i := strings.Index(entry, ":"); i >= 0 {
+		platform, entryTags = entry[:i], entry[i+1:]
+	}
+	context := &buildContext{
+		GOOS:   defaultGoEnv("GOOS", runtime.GOOS),
+		GOARCH: defaultGoEnv("GOARCH", runtime.GOARCH),
+		Tags:   append(append([]string{}, tags...), splitBuildTags([]string{entryTags})...),
+		matrix: true,
+	}
+	if platform != "" {
+		parts := strings.Split(platform, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid build matrix entry %q, expected GOOS/GOARCH[:TAG,...]", entry)
+		}
+		context.GOOS, context.GOARCH = parts[0], parts[1]
+	}
+	return context, nil
+}
+
+// buildContexts returns the contexts that linters are run under: one for each
+// entry of the build matrix, or a single context with the configured tags.
+func buildContexts(config *Config) ([]*buildContext, error) {
+	tags := splitBuildTags(config.Tags)
+	if len(config.Matrix) == 0 {
+		return []*buildContext{{Tags: tags}}, nil
+	}
+	out := []*buildContext{}
+	seen := map[string]bool{}
+	for _, entry := range config.Matrix {
+		context, err := parseBuildContext(entry, tags)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[context.String()] {
+			seen[context.String()] = true
+			out = append(out, context)
+		}
+	}
+	return out, nil
+}
+
+// mergeBuildContextIssues merges issues reported under several build
+// contexts, such that each is reported once with all of the contexts it was
+// reported under.
+func mergeBuildContextIssues(issues chan *Issue) chan *Issue {
+	out := make(chan *Issue, 1000000)
+	go func() {
+		merged := map[string]*Issue{}
+		order := []string{}
+		for issue := range issues {
+			key := fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%s", issue.Linter, issue.Path, issue.Line, issue.Col, issue.Message)
+			existing, ok := merged[key]
+			if !ok {
+				merged[key] = issue
+				order = append(order, key)
+				continue
+			}
+			for _, context := range issue.BuildContexts {
+				if !stringsContain(existing.BuildContexts, context) {
+					existing.BuildContexts = append(existing.BuildContexts, context)
+				}
+			}
+		}
+		for _, key := range order {
+			issue := merged[key]
+			sort.Strings(issue.BuildContexts)
+			out <- issue
+		}
+		close(out)
+	}()
+	return out
+}
+
+func maybeMergeBuildContextIssues(issues chan *Issue) chan *Issue {
+	if len(config.Matrix) == 0 {
+		return issues
+	}
+	return mergeBuildContextIssues(issues)
+}
+
+func stringsContain(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildContexts(t *testing.T) {
+	contexts, err := buildContexts(&Config{Tags: []string{"a,b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []*buildContext{{Tags: []string{"a", "b"}}}, contexts)
+
+	contexts, err = buildContexts(&Config{
+		Tags:   []string{"a"},
+		Matrix: []string{"linux/amd64", "windows/386:integration,slow", "linux/amd64"},
+	})
+	require.NoError(t, err)
+	actual := []string{}
+	for _, context := range contexts {
+		actual = append(actual, context.String())
+	}
+	assert.Equal(t, []string{"linux/amd64:a", "windows/386:a,integration,slow"}, actual)
+	assert.Equal(t, []string{"GOOS=windows", "GOARCH=386"}, contexts[1].env())
+
+	_, err = buildContexts(&Config{Matrix: []string{"linux"}})
+	assert.EqualError(t, err, `invalid build matrix entry "linux", expected GOOS/GOARCH[:TAG,...]`)
+}
+
+func TestMergeBuildContextIssues(t *testing.T) {
+	issues := make(chan *Issue, 4)
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 1, Message: "bad", BuildContexts: []string{"windows/amd64"}}
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 2, Message: "bad", BuildContexts: []string{"windows/amd64"}}
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 1, Message: "bad", BuildContexts: []string{"linux/amd64"}}
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 1, Message: "bad", BuildContexts: []string{"linux/amd64"}}
+	close(issues)
+
+	actual := []*Issue{}
+	for issue := range mergeBuildContextIssues(issues) {
+		actual = append(actual, issue)
+	}
+	require.Len(t, actual, 2)
+	assert.Equal(t, []string{"linux/amd64", "windows/amd64"}, actual[0].BuildContexts)
+	assert.Equal(t, []string{"windows/amd64"}, actual[1].BuildContexts)
+}
+
+func TestRunLintersWithMatrix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	config.Matrix = []string{"linux/amd64", "windows/amd64:integration"}
+
+	mkDir(t, tmpdir, "pkg")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$2/file.go:1: everywhere\"\necho \"$2/file.go:2: only $GOOS $1\"\n"), 0755)
+	require.NoError(t, err)
+	linterConfig, err := parseLinterConfigSpec("fake", script+" tags={tags}:PATH:LINE:MESSAGE")
+	require.NoError(t, err)
+	config.Linters["fake"] = StringOrLinterConfig(linterConfig)
+	config.Enable = []string{"fake"}
+
+	issues, errch, _ := runLinters(lintersFromConfig(config), []string{"./pkg"}, 2, nil, nil, nil)
+	actual := map[string][]string{}
+	for issue := range issues {
+		actual[issue.Message] = issue.BuildContexts
+	}
+	for err := range errch {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string][]string{
+		"everywhere":                    {"linux/amd64", "windows/amd64:integration"},
+		"only linux tags=":              {"linux/amd64"},
+		"only windows tags=integration": {"windows/amd64:integration"},
+	}, actual)
+}
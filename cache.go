@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/shlex"
+)
+
+// resultCache stores the output of linter executions on disk, keyed by the
+// linter, its resolved command, the partition it was run over and the content
+// of every Go file in that partition.
+//
+// Unless a linter is Cacheable, its output may depend on imported packages, so
+// the files of the partition's packages and all of their dependencies, as
+// reported by "go list", are hashed too.
+type resultCache struct {
+	dir     string
+	maxSize int64
+
+	lock sync.Mutex
+	// Packages already listed and hashed during this run, keyed by build
+	// context and import path.
+	packages map[string]*cachedPackage
+}
+
+// cachedPackage is a package listed by "go list".
+type cachedPackage struct {
+	hash string
+	deps []string
+}
+
+func newResultCache(dir string, maxSize int64) *resultCache {
+	return &resultCache{dir: dir, maxSize: maxSize, packages: map[string]*cachedPackage{}}
+}
+
+func defaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "gometalinter")
+	}
+	user, err := user.Current()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "gometalinter-cache")
+	}
+	return filepath.Join(user.HomeDir, ".cache", "gometalinter")
+}
+
+// key returns the cache key for running state with args. Returns false if
+// the key could not be computed, in which case the result should not be cached.
+func (c *resultCache) key(state *linterState, args []string) (string, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "linter=%s\x00command=%s\x00stream=%s\x00cwd=%s\x00", state.Name, state.command(), state.Stream, cwd)
+	for _, env := range state.env() {
+		fmt.Fprintf(h, "env=%s\x00", env)
+	}
+	if len(args) > 0 {
+		// Include the linter binary so that upgrading a linter invalidates the cache.
+		if info, err := os.Stat(args[0]); err == nil {
+			fmt.Fprintf(h, "exe=%s:%d:%d\x00", args[0], info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	command, err := shlex.Split(state.command())
+	if err != nil || len(command) > len(args) {
+		return "", false
+	}
+	for _, arg := range args[:len(command)] {
+		fmt.Fprintf(h, "arg=%s\x00", arg)
+	}
+	for _, arg := range args[len(command):] {
+		fmt.Fprintf(h, "arg=%s\x00", arg)
+		files, err := cacheInputFiles(arg)
+		if err != nil {
+			return "", false
+		}
+		for _, file := range files {
+			if err := hashFile(h, file); err != nil {
+				return "", false
+			}
+		}
+	}
+	if state.Cacheable == nil {
+		if err := c.hashDependencies(h, state, args[len(command):]); err != nil {
+			debug("can't cache %s: %s", state.Name, err)
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), true
+}
+
+// hashDependencies writes the hash of every file in the packages of paths, and
+// in the packages they and their tests import, to w.
+func (c *resultCache) hashDependencies(w io.Writer, state *linterState, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	tags := []string{}
+	if state.buildContext != nil {
+		tags = state.buildContext.Tags
+	}
+	context := fmt.Sprintf("%s\x00%s", strings.Join(state.env(), " "), strings.Join(tags, " "))
+
+	out, err := goList(state.env(), tags, `{{.ImportPath}}{{"\n"}}{{join .Deps "\n"}}{{"\n"}}{{join .TestImports "\n"}}{{"\n"}}{{join .XTestImports "\n"}}`, goListTargets(paths))
+	if err != nil {
+		return err
+	}
+	seen := newStringSet()
+	pending := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" && !seen.contains(line) {
+			seen.add(line)
+			pending = append(pending, line)
+		}
+	}
+	// Packages imported only by tests bring their own dependencies, so keep
+	// listing until every package has been seen.
+	for len(pending) > 0 {
+		packages, err := c.listPackages(state.env(), tags, context, pending)
+		if err != nil {
+			return err
+		}
+		pending = []string{}
+		for _, pkg := range packages {
+			for _, dep := range pkg.deps {
+				if !seen.contains(dep) {
+					seen.add(dep)
+					pending = append(pending, dep)
+				}
+			}
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	paths = seen.asSlice()
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(w, "package=%s:%s\x00", path, c.packages[context+"\x00"+path].hash)
+	}
+	return nil
+}
+
+// listPackages returns the named packages, listing and hashing those not
+// already seen in context.
+func (c *resultCache) listPackages(env []string, tags []string, context string, paths []string) ([]*cachedPackage, error) {
+	c.lock.Lock()
+	missing := []string{}
+	for _, path := range paths {
+		if c.packages[context+"\x00"+path] == nil {
+			missing = append(missing, path)
+		}
+	}
+	c.lock.Unlock()
+
+	if len(missing) > 0 {
+		out, err := goList(env, tags, `{{.ImportPath}}{{"\t"}}{{.Standard}}{{"\t"}}{{.Dir}}{{"\t"}}{{join .GoFiles " "}} {{join .CgoFiles " "}}{{"\t"}}{{join .Deps " "}}`, missing)
+		if err != nil {
+			return nil, err
+		}
+		listed := map[string]*cachedPackage{}
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) != 5 {
+				continue
+			}
+			pkg, err := hashPackage(fields[1] == "true", fields[2], strings.Fields(fields[3]))
+			if err != nil {
+				return nil, err
+			}
+			pkg.deps = strings.Fields(fields[4])
+			listed[fields[0]] = pkg
+		}
+		c.lock.Lock()
+		for _, path := range missing {
+			pkg := listed[path]
+			if pkg == nil {
+				// Not listed, such as the "C" pseudo-package.
+				pkg = &cachedPackage{hash: "missing"}
+			}
+			c.packages[context+"\x00"+path] = pkg
+		}
+		c.lock.Unlock()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	out := []*cachedPackage{}
+	for _, path := range paths {
+		out = append(out, c.packages[context+"\x00"+path])
+	}
+	return out, nil
+}
+
+// hashPackage hashes the content of a package's files. Files in the standard
+// library only change when Go is upgraded, so their size and modification
+// time are hashed instead.
+func hashPackage(standard bool, dir string, files []string) (*cachedPackage, error) {
+	h := sha256.New()
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if standard {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(h, "file=%s:%d:%d\x00", path, info.Size(), info.ModTime().UnixNano())
+			continue
+		}
+		if err := hashFile(h, path); err != nil {
+			return nil, err
+		}
+	}
+	return &cachedPackage{hash: fmt.Sprintf("%x", h.Sum(nil))}, nil
+}
+
+// goListTargets returns the packages containing paths, in the form accepted
+// by "go list".
+func goListTargets(paths []string) []string {
+	targets := newStringSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".go") {
+			path = filepath.Dir(path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() && !filepath.IsAbs(path) && !strings.HasPrefix(path, ".") {
+			// Relative directories are otherwise taken to be import paths.
+			path = "." + string(filepath.Separator) + path
+		}
+		targets.add(path)
+	}
+	return targets.asSlice()
+}
+
+// goList runs "go list -e -f format" over packages in the given build context.
+func goList(env []string, tags []string, format string, packages []string) (string, error) {
+	args := []string{"list", "-e", "-f", format}
+	if len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, " "))
+	}
+	cmd := exec.Command("go", append(args, packages...)...) // nolint: gas
+	cmd.Env = append(os.Environ(), env...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go list failed: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// cacheInputFiles returns the Go files that a path passed to a linter refers
+// to, be it a file, a directory or a package import path. An error is returned
+// if the path can not be resolved.
+func cacheInputFiles(arg string) ([]string, error) {
+	if strings.HasSuffix(arg, ".go") {
+		if _, err := os.Stat(arg); err == nil {
+			return []string{arg}, nil
+		}
+	}
+	dir, ok := resolvePackageDir(arg)
+	if !ok {
+		return nil, fmt.Errorf("can't resolve %s to files", arg)
+	}
+	files, err := pathsToFileGlobs([]string{dir})
+	sort.Strings(files)
+	return files, err
+}
+
+// resolvePackageDir returns the directory of a package passed to a linter as
+// a directory or an import path, in the current module or in GOPATH.
+func resolvePackageDir(arg string) (string, bool) {
+	dir := resolveImportPath(arg)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir, true
+	}
+	for _, gopath := range getGoPathList() {
+		candidate := filepath.Join(gopath, "src", arg)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func hashFile(w io.Writer, path string) error {
+	r, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close() // nolint: errcheck
+	fmt.Fprintf(w, "file=%s\x00", path)
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func (c *resultCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// get returns the cached output for key, if any.
+func (c *resultCache) get(key string) ([]byte, bool) {
+	path := c.path(key)
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	// Track use for eviction.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return out, true
+}
+
+// put stores the output for key.
+func (c *resultCache) put(key string, out []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), key+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(out); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+type cacheEntriesByAge []cacheEntry
+
+func (c cacheEntriesByAge) Len() int           { return len(c) }
+func (c cacheEntriesByAge) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c cacheEntriesByAge) Less(i, j int) bool { return c[i].modTime.Before(c[j].modTime) }
+
+// evict removes the least recently used entries until the cache is no larger
+// than its maximum size.
+func (c *resultCache) evict() error {
+	entries := []cacheEntry{}
+	total := int64(0)
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		entries = append(entries, cacheEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil || total <= c.maxSize {
+		return err
+	}
+	sort.Sort(cacheEntriesByAge(entries))
+	for _, entry := range entries {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil {
+			return err
+		}
+		total -= entry.size
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCacheKey(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "pkg")
+	cache := newResultCache(filepath.Join(tmpdir, "cache"), 1024)
+	state := &linterState{
+		Linter: getLinterByName("golint", LinterConfig{}),
+		vars:   Vars{"min_confidence": "0.8"},
+	}
+
+	args := []string{"golint", "-min_confidence", "0.8", "./pkg"}
+	key, ok := cache.key(state, args)
+	require.True(t, ok)
+	again, ok := cache.key(state, args)
+	require.True(t, ok)
+	assert.Equal(t, key, again)
+
+	err := ioutil.WriteFile(filepath.Join("pkg", "other.go"), []byte("package foo\n"), 0644)
+	require.NoError(t, err)
+	changed, ok := cache.key(state, args)
+	require.True(t, ok)
+	assert.NotEqual(t, key, changed)
+
+	state.vars = Vars{"min_confidence": "0.5"}
+	reconfigured, ok := cache.key(state, []string{"golint", "-min_confidence", "0.5", "./pkg"})
+	require.True(t, ok)
+	assert.NotEqual(t, changed, reconfigured)
+
+	// Paths that don't resolve to files can't be cached.
+	_, ok = cache.key(state, []string{"golint", "-min_confidence", "0.5", "example.com/missing"})
+	assert.False(t, ok)
+}
+
+func TestResultCacheKeyIncludesDependencies(t *testing.T) {
+	defer enableModules(t)()
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkGoMod(t, tmpdir, "module example.com/app\n")
+	mkDir(t, tmpdir, "dep")
+	mkDir(t, tmpdir, "pkg")
+	err := ioutil.WriteFile(filepath.Join("pkg", "file.go"), []byte("package pkg\n\nimport _ \"example.com/app/dep\"\n"), 0644)
+	require.NoError(t, err)
+
+	filesOnlyState := &linterState{Linter: getLinterByName("gofmt", LinterConfig{})}
+	importsState := &linterState{
+		Linter: getLinterByName("vet", LinterConfig{}),
+		vars:   Vars{"tags": ""},
+	}
+	filesOnlyArgs := []string{"gofmt", "-l", "-s", "./pkg"}
+	importsArgs := []string{"govet", "--no-recurse", "./pkg"}
+
+	cache := newResultCache(filepath.Join(tmpdir, "cache"), 1024)
+	filesOnlyKey, ok := cache.key(filesOnlyState, filesOnlyArgs)
+	require.True(t, ok)
+	importsKey, ok := cache.key(importsState, importsArgs)
+	require.True(t, ok)
+	again, ok := cache.key(importsState, importsArgs)
+	require.True(t, ok)
+	assert.Equal(t, importsKey, again)
+
+	// Changing an imported package only invalidates linters that inspect it.
+	err = ioutil.WriteFile(filepath.Join("dep", "other.go"), []byte("package foo\n"), 0644)
+	require.NoError(t, err)
+	cache = newResultCache(filepath.Join(tmpdir, "cache"), 1024)
+	changed, ok := cache.key(filesOnlyState, filesOnlyArgs)
+	require.True(t, ok)
+	assert.Equal(t, filesOnlyKey, changed)
+	changed, ok = cache.key(importsState, importsArgs)
+	require.True(t, ok)
+	assert.NotEqual(t, importsKey, changed)
+}
+
+func TestResultCachePutGet(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	cache := newResultCache(filepath.Join(tmpdir, "cache"), 1024)
+	_, ok := cache.get("abcdef")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.put("abcdef", []byte("output")))
+	out, ok := cache.get("abcdef")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("output"), out)
+}
+
+func TestResultCacheEvict(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	cache := newResultCache(filepath.Join(tmpdir, "cache"), 10)
+	require.NoError(t, cache.put("aaaa", []byte("123456")))
+	require.NoError(t, cache.put("bbbb", []byte("123456")))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(cache.path("aaaa"), old, old))
+
+	require.NoError(t, cache.evict())
+	_, ok := cache.get("aaaa")
+	assert.False(t, ok)
+	_, ok = cache.get("bbbb")
+	assert.True(t, ok)
+}
+
+func TestCacheInputFilesInModule(t *testing.T) {
+	defer enableModules(t)()
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkGoMod(t, tmpdir, "module example.com/app\n")
+	mkDir(t, tmpdir, "pkg")
+	files, err := cacheInputFiles("example.com/app/pkg")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("pkg", "file.go")}, files)
+
+	_, err = cacheInputFiles("example.com/app/missing")
+	assert.Error(t, err)
+}
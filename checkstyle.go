@@ -52,7 +52,7 @@ func outputToCheckstyle(issues chan *Issue) int {
 			Line:     issue.Line,
 			Message:  issue.Message,
 			Severity: string(issue.Severity),
-			Source:   issue.Linter,
+			Source:   checkstyleSource(issue),
 		})
 		status = 1
 	}
@@ -64,3 +64,12 @@ func outputToCheckstyle(issues chan *Issue) int {
 	fmt.Printf("%s%s\n", xml.Header, d)
 	return status
 }
+
+// checkstyleSource identifies the check that reported an issue, as
+// "linter.rule" or just "linter".
+func checkstyleSource(issue *Issue) string {
+	if issue.Rule == "" {
+		return issue.Linter
+	}
+	return issue.Linter + "." + issue.Rule
+}
@@ -2,13 +2,23 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"text/template"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
-// Config for gometalinter. This can be loaded from a JSON file with --config.
+// Config for gometalinter. This can be loaded from a JSON, YAML or TOML file
+// with --config, or discovered automatically (see configFileNames).
 type Config struct { // nolint: maligned
+	// Configuration files to load before this one, relative to this one.
+	Extends []string
+
 	// A map from linter name -> <LinterConfig|string>.
 	//
 	// For backwards compatibility, the value stored in the JSON blob can also
@@ -33,8 +43,10 @@ type Config struct { // nolint: maligned
 	Debug           bool
 	Concurrency     int
 	Exclude         []string
+	ExcludeRules    []*ExcludeRule
 	Include         []string
 	Skip            []string
+	GitIgnore       bool
 	Vendor          bool
 	Cyclo           int
 	LineLength      int
@@ -44,18 +56,173 @@ type Config struct { // nolint: maligned
 	DuplThreshold   int
 	Sort            []string
 	Test            bool
-	Deadline        jsonDuration
-	Errors          bool
-	JSON            bool
-	Checkstyle      bool
-	EnableGC        bool
-	Aggregate       bool
-	EnableAll       bool
+	// Build tags passed to linters that support them, via {tags}.
+	Tags []string
+	// Build contexts to run linters under, merging their results. Each is
+	// "GOOS/GOARCH", optionally followed by ":" and comma separated build
+	// tags.
+	Matrix   []string
+	Deadline jsonDuration
+	// Deadline for the whole run. Zero means no deadline.
+	TotalDeadline jsonDuration
+	Errors        bool
+	JSON          bool
+	Checkstyle    bool
+	SARIF         bool
+	JUnit         bool
+	GitLab        bool
+	EnableGC      bool
+	Aggregate     bool
+	EnableAll     bool
+
+	// Settings applied only to packages matching path globs.
+	Overrides []*ConfigOverride
 
 	// Warn if a nolint directive was never matched to a linter issue
 	WarnUnmatchedDirective bool
 
+	// Skip issues in generated files, which are detected by the standard
+	// "// Code generated ... DO NOT EDIT." comment or by any of
+	// GeneratedMarkers in a comment before the package clause.
+	SkipGenerated    bool
+	GeneratedMarkers []string
+
+	// Re-lint packages when their files change.
+	Watch bool
+
+	// Fix issues with the Fix command of each linter, then lint again. With
+	// DryRun, output the fixes as a unified diff instead of writing them.
+	Fix    bool
+	DryRun bool
+
+	// Cache linter output, keyed by the content of the files linted.
+	Cache     bool
+	CacheDir  string
+	CacheSize int // In megabytes.
+
+	// Only report issues on lines added or modified since a git revision, or
+	// by a unified diff.
+	NewFromRev    string
+	NewFromPatch  string
+	NewFileIssues bool
+
+	// Suppress issues recorded in a baseline file, or record current issues
+	// to a new baseline file.
+	Baseline      string
+	WriteBaseline string
+
+	configFile     string
+	configSources  map[string][]string
 	formatTemplate *template.Template
+	changedLines   changedLines
+	excludeRules   []*compiledExcludeRule
+	baseline       *baseline
+}
+
+// clone returns a copy of the config that shares no maps or slices with c.
+func (c *Config) clone() *Config {
+	out := *c
+	out.Linters = make(map[string]StringOrLinterConfig, len(c.Linters))
+	for k, v := range c.Linters {
+		out.Linters[k] = v
+	}
+	out.MessageOverride = copyStringMap(c.MessageOverride)
+	out.Severity = copyStringMap(c.Severity)
+	out.Enable = append([]string(nil), c.Enable...)
+	out.Disable = append([]string(nil), c.Disable...)
+	out.Exclude = append([]string(nil), c.Exclude...)
+	out.ExcludeRules = append([]*ExcludeRule(nil), c.ExcludeRules...)
+	out.Include = append([]string(nil), c.Include...)
+	out.Skip = append([]string(nil), c.Skip...)
+	out.Sort = append([]string(nil), c.Sort...)
+	out.Tags = append([]string(nil), c.Tags...)
+	out.Matrix = append([]string(nil), c.Matrix...)
+	out.GeneratedMarkers = append([]string(nil), c.GeneratedMarkers...)
+	out.Extends = append([]string(nil), c.Extends...)
+	out.Overrides = append([]*ConfigOverride(nil), c.Overrides...)
+	return &out
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// Names of the configuration files searched for in the working directory and
+// each of its parents, in order of preference.
+var configFileNames = []string{
+	".gometalinter.json",
+	".gometalinter.yaml",
+	".gometalinter.yml",
+	".gometalinter.toml",
+}
+
+// findConfigFile returns the path of the configuration file closest to dir,
+// or "" if there is none.
+func findConfigFile(dir string) string {
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// convertConfigFile returns the contents of a configuration file as JSON.
+// The format is determined by the file extension, defaulting to JSON. YAML
+// and TOML are converted to JSON so that they support the same types.
+func convertConfigFile(path string, data []byte) ([]byte, error) {
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var document interface{}
+		if err = yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		data, err = json.Marshal(yamlToJSONValue(document))
+
+	case ".toml":
+		var document map[string]interface{}
+		if document, err = parseTOML(data); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		data, err = json.Marshal(document)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return data, nil
+}
+
+// yamlToJSONValue converts the maps decoded by YAML, which may have keys of
+// any type, into maps that can be encoded as JSON.
+func yamlToJSONValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			out[fmt.Sprintf("%v", k)] = yamlToJSONValue(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, v := range value {
+			out[i] = yamlToJSONValue(v)
+		}
+		return out
+	default:
+		return value
+	}
 }
 
 type StringOrLinterConfig LinterConfig
@@ -94,6 +261,10 @@ func (td *jsonDuration) UnmarshalJSON(raw []byte) error {
 	return err
 }
 
+func (td jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(td).String())
+}
+
 // Duration returns the value as a time.Duration
 func (td *jsonDuration) Duration() time.Duration {
 	return time.Duration(*td)
@@ -134,4 +305,8 @@ var config = &Config{
 	DuplThreshold:   50,
 	Sort:            []string{"none"},
 	Deadline:        jsonDuration(time.Second * 30),
+	SkipGenerated:   true,
+	Cache:           true,
+	CacheDir:        defaultCacheDir(),
+	CacheSize:       100,
 }
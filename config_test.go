@@ -2,7 +2,10 @@ package main
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,3 +22,66 @@ func TestLinterConfigUnmarshalJSON(t *testing.T) {
 	assert.Equal(t, "/bin/custom", config.Command)
 	assert.Equal(t, functionName(partitionPathsAsDirectories), functionName(config.PartitionStrategy))
 }
+
+func TestFindConfigFile(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "a", "b")
+	assert.Equal(t, "", findConfigFile(filepath.Join(tmpdir, "a", "b")))
+
+	path := filepath.Join(tmpdir, "a", ".gometalinter.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("Fast: true\n"), 0644))
+	assert.Equal(t, path, findConfigFile(filepath.Join(tmpdir, "a", "b")))
+
+	preferred := filepath.Join(tmpdir, "a", ".gometalinter.json")
+	require.NoError(t, ioutil.WriteFile(preferred, []byte("{}"), 0644))
+	assert.Equal(t, preferred, findConfigFile(filepath.Join(tmpdir, "a")))
+}
+
+func TestDecodeConfigFile(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	var testcases = []struct {
+		name   string
+		source string
+	}{
+		{
+			name: ".gometalinter.json",
+			source: `{
+				"Enable": ["golint", "custom"],
+				"Deadline": "2m",
+				"Linters": {"custom": "custom-lint:PATH:LINE:MESSAGE"}
+			}`,
+		},
+		{
+			name: ".gometalinter.yaml",
+			source: `
+enable: [golint, custom]
+deadline: 2m
+linters:
+  custom: custom-lint:PATH:LINE:MESSAGE
+`,
+		},
+		{
+			name: ".gometalinter.toml",
+			source: `
+Enable = ["golint", "custom"]
+Deadline = "2m"
+
+[Linters]
+custom = "custom-lint:PATH:LINE:MESSAGE"
+`,
+		},
+	}
+	for _, testcase := range testcases {
+		path := filepath.Join(tmpdir, testcase.name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(testcase.source), 0644))
+		actual := &Config{}
+		require.NoError(t, newConfigLoader(actual).load(path), testcase.name)
+		assert.Equal(t, []string{"golint", "custom"}, actual.Enable, testcase.name)
+		assert.Equal(t, 2*time.Minute, actual.Deadline.Duration(), testcase.name)
+		assert.Equal(t, "custom-lint", actual.Linters["custom"].Command, testcase.name)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// resolvedLinter is a linter as it will be run.
+type resolvedLinter struct {
+	Name              string
+	Command           string
+	Format            string `json:",omitempty"`
+	Pattern           string
+	PartitionStrategy string
+	Severity          string
+	MessageOverride   string `json:",omitempty"`
+	Fix               string `json:",omitempty"`
+	IsFast            bool
+	InstallFrom       string `json:",omitempty"`
+}
+
+// effectiveConfig is the output of "config print".
+type effectiveConfig struct {
+	Config  *Config
+	Linters []*resolvedLinter
+}
+
+func newEffectiveConfig(config *Config, linters map[string]*Linter) *effectiveConfig {
+	vars := linterVars(config)
+	out := &effectiveConfig{Config: config, Linters: []*resolvedLinter{}}
+	for _, linter := range linters {
+		severity := config.Severity[linter.Name]
+		if severity == "" {
+			severity = string(Warning)
+		}
+		out.Linters = append(out.Linters, &resolvedLinter{
+			Name:              linter.Name,
+			Command:           vars.Replace(linter.Command),
+			Format:            linter.Format,
+			Pattern:           linter.regex.String(),
+			PartitionStrategy: linter.PartitionStrategy.String(),
+			Severity:          severity,
+			MessageOverride:   config.MessageOverride[linter.Name],
+			Fix:               vars.Replace(linter.Fix),
+			IsFast:            linter.IsFast,
+			InstallFrom:       linter.InstallFrom,
+		})
+	}
+	sort.Sort(resolvedLinters(out.Linters))
+	return out
+}
+
+type resolvedLinters []*resolvedLinter
+
+func (r resolvedLinters) Len() int           { return len(r) }
+func (r resolvedLinters) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r resolvedLinters) Less(i, j int) bool { return r[i].Name < r[j].Name }
+
+// printConfig writes the effective configuration and linters to w, as
+// "json" or "yaml".
+func printConfig(w io.Writer, config *Config, linters map[string]*Linter, format string) error {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(newEffectiveConfig(config, linters)); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if format == "yaml" {
+		// Round trip through an ordered map to preserve the field order.
+		document := yaml.MapSlice{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return err
+		}
+		var err error
+		if data, err = yaml.Marshal(document); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// validateConfig returns every problem found in config, including those
+// which are otherwise ignored or only reported when linting.
+func validateConfig(config *Config) []error {
+	errs := []error{}
+	if _, err := newIssueFormatTemplate(config.Format); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Format: %s", err))
+	}
+	for _, pattern := range config.Exclude {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Exclude regexp %q: %s", pattern, err))
+		}
+	}
+	for _, pattern := range config.Include {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Include regexp %q: %s", pattern, err))
+		}
+	}
+	if _, err := compileExcludeRules(config.ExcludeRules); err != nil {
+		errs = append(errs, fmt.Errorf("invalid ExcludeRules: %s", err))
+	}
+	if _, err := buildContexts(config); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Matrix: %s", err))
+	}
+	if err := validateOverrides(config); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Overrides: %s", err))
+	}
+	validSortKeys := newStringSet(sortKeys...)
+	for _, key := range config.Sort {
+		if _, ok := validSortKeys.items[key]; !ok {
+			errs = append(errs, fmt.Errorf("invalid Sort key %q", key))
+		}
+	}
+
+	known := func(name string) bool {
+		_, isDefault := defaultLinters[name]
+		_, isCustom := config.Linters[name]
+		return isDefault || isCustom
+	}
+	for _, setting := range []struct {
+		name    string
+		linters []string
+	}{
+		{"Enable", config.Enable},
+		{"Disable", config.Disable},
+		{"Severity", stringMapKeys(config.Severity)},
+		{"MessageOverride", stringMapKeys(config.MessageOverride)},
+	} {
+		for _, name := range setting.linters {
+			if known(name) {
+				continue
+			}
+			err := fmt.Errorf("unknown linter %q in %s", name, setting.name)
+			if suggestion := suggestName(name, knownLinterNames(config)); suggestion != "" {
+				err = fmt.Errorf("%s, did you mean %q?", err, suggestion)
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	names := []string{}
+	for name := range config.Linters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := newLinterByName(name, LinterConfig(config.Linters[name])); err != nil {
+			errs = append(errs, fmt.Errorf("invalid linter %s: %s", name, err))
+		}
+	}
+	return errs
+}
+
+func stringMapKeys(m map[string]string) []string {
+	out := []string{}
+	for key := range m {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
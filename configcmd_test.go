@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionStrategyMarshalJSON(t *testing.T) {
+	for name := range partitionStrategies {
+		var strategy partitionStrategy
+		require.NoError(t, json.Unmarshal([]byte(`"`+name+`"`), &strategy))
+		encoded, err := json.Marshal(strategy)
+		require.NoError(t, err)
+		assert.Equal(t, `"`+name+`"`, string(encoded))
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	config := &Config{
+		Enable:          []string{"golint"},
+		MinConfidence:   0.5,
+		MessageOverride: map[string]string{"golint": "lint: {message}"},
+	}
+	buf := &bytes.Buffer{}
+	err := printConfig(buf, config, lintersFromConfig(config), "json")
+	require.NoError(t, err)
+
+	printed := &effectiveConfig{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), printed))
+	assert.Equal(t, []string{"golint"}, printed.Config.Enable)
+	assert.Equal(t, []*resolvedLinter{{
+		Name:              "golint",
+		Command:           "golint -min_confidence 0.500000",
+		Pattern:           getLinterByName("golint", LinterConfig{}).regex.String(),
+		PartitionStrategy: "directories",
+		Severity:          "warning",
+		MessageOverride:   "lint: {message}",
+		IsFast:            true,
+		InstallFrom:       "github.com/golang/lint/golint",
+	}}, printed.Linters)
+
+	buf.Reset()
+	err = printConfig(buf, config, lintersFromConfig(config), "yaml")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Linters:\n- Name: golint\n")
+}
+
+func TestValidateConfig(t *testing.T) {
+	config := &Config{
+		Format:  DefaultIssueFormat,
+		Enable:  []string{"golint", "golnt", "nosuch"},
+		Exclude: []string{"(("},
+		Linters: map[string]StringOrLinterConfig{
+			"custom": {Command: "custom", Pattern: "(("},
+		},
+		Severity: map[string]string{"other": "error"},
+	}
+	errs := []string{}
+	for _, err := range validateConfig(config) {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		"invalid Exclude regexp \"((\": error parsing regexp: missing closing ): `((`",
+		`unknown linter "golnt" in Enable, did you mean "golint"?`,
+		`unknown linter "nosuch" in Enable`,
+		`unknown linter "other" in Severity`,
+		"invalid linter custom: error parsing regexp: missing closing ): `(?m:(()`",
+	}, errs)
+
+	assert.Empty(t, validateConfig(&Config{Format: DefaultIssueFormat, Enable: []string{"golint"}}))
+}
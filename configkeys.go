@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	configType     = reflect.TypeOf(Config{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	anyJSONType    = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// unknownConfigKey is a key in a configuration file that does not correspond
+// to a setting.
+type unknownConfigKey struct {
+	key string
+	// Offset of the key in the JSON encoded configuration.
+	offset     int
+	suggestion string
+}
+
+// checkConfigKeys returns an error describing each unknown key in a
+// configuration file, including those in nested settings such as
+// ExcludeRules. data is the file converted to JSON, and raw its original
+// contents.
+func checkConfigKeys(path string, raw, data []byte) error {
+	checker := &configKeyChecker{dec: json.NewDecoder(bytes.NewReader(data)), data: data}
+	// Syntax errors are reported when the file is decoded.
+	if err := checker.value(configType); err != nil || len(checker.unknown) == 0 {
+		return nil
+	}
+	lines := []string{}
+	for _, unknown := range checker.unknown {
+		message := fmt.Sprintf("unknown key %q", unknown.key)
+		if unknown.suggestion != "" {
+			message += fmt.Sprintf(", did you mean %q?", unknown.suggestion)
+		}
+		lines = append(lines, formatConfigPosition(path, raw, data, unknown.offset, unknown.key)+message)
+	}
+	return fmt.Errorf("%s", strings.Join(lines, "\n"))
+}
+
+// newConfigFileError adds the position of the error, if known, to an error
+// decoding the configuration file at path.
+func newConfigFileError(path string, raw, data []byte, err error) error {
+	switch err := err.(type) {
+	case *json.SyntaxError:
+		return fmt.Errorf("%s%s", formatConfigPosition(path, raw, data, int(err.Offset), ""), err)
+	case *json.UnmarshalTypeError:
+		return fmt.Errorf("%s%s", formatConfigPosition(path, raw, data, int(err.Offset), ""), err)
+	}
+	return fmt.Errorf("%s: %s", path, err)
+}
+
+// formatConfigPosition returns "path:line:col: " for the given offset in
+// data. YAML and TOML files are converted to JSON before decoding, so for
+// those the position of the first occurrence of key in raw is used instead,
+// falling back to just the path.
+func formatConfigPosition(path string, raw, data []byte, offset int, key string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml":
+		offset = -1
+		if key != "" {
+			pattern := regexp.MustCompile(`(^|[^A-Za-z0-9_])` + regexp.QuoteMeta(key) + `($|[^A-Za-z0-9_])`)
+			if match := pattern.FindIndex(raw); match != nil {
+				offset = match[0]
+				if !bytes.HasPrefix(raw[offset:], []byte(key)) {
+					offset++
+				}
+			}
+		}
+	default:
+		raw = data
+	}
+	if offset < 0 || offset > len(raw) {
+		return path + ": "
+	}
+	line := bytes.Count(raw[:offset], []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(raw[:offset], '\n')
+	return fmt.Sprintf("%s:%d:%d: ", path, line, col)
+}
+
+// configKeyChecker walks JSON tokens alongside the type they decode into,
+// recording object keys that do not match a struct field.
+type configKeyChecker struct {
+	dec     *json.Decoder
+	data    []byte
+	unknown []*unknownConfigKey
+}
+
+// value checks the next JSON value, which decodes into t.
+func (c *configKeyChecker) value(t reflect.Type) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// The only raw settings are the partial Configs in Overrides.
+	if t == rawMessageType {
+		t = configType
+	}
+	token, err := c.dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+	for c.dec.More() {
+		elem := anyJSONType
+		if delim == '[' {
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+				elem = t.Elem()
+			}
+		} else {
+			token, err := c.dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			switch t.Kind() {
+			case reflect.Map:
+				elem = t.Elem()
+			case reflect.Struct:
+				if field, ok := jsonFieldByKey(t, key); ok {
+					elem = field.Type
+				} else {
+					c.unknown = append(c.unknown, &unknownConfigKey{
+						key:        key,
+						offset:     c.keyOffset(),
+						suggestion: suggestName(key, jsonFieldNames(t)),
+					})
+				}
+			}
+		}
+		if err := c.value(elem); err != nil {
+			return err
+		}
+	}
+	_, err = c.dec.Token()
+	return err
+}
+
+// keyOffset returns the offset of the opening quote of the key just read.
+func (c *configKeyChecker) keyOffset() int {
+	end := int(c.dec.InputOffset())
+	return bytes.LastIndexByte(c.data[:end-1], '"')
+}
+
+// jsonFieldByKey returns the field of struct type t that encoding/json would
+// decode key into.
+func jsonFieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := jsonFieldName(field); name != "" && strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	out := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "" {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// jsonFieldName returns the JSON key of a struct field, or "" if it is not
+// encoded.
+func jsonFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	tag := strings.Split(field.Tag.Get("json"), ",")[0]
+	switch tag {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return tag
+}
+
+// suggestName returns the candidate closest to name, ignoring case, or "" if
+// none are close enough to be a likely typo.
+func suggestName(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/3+1
+	if bestDistance < 2 {
+		bestDistance = 2
+	}
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance <= bestDistance && (best == "" || distance < bestDistance) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	out := values[0]
+	for _, value := range values[1:] {
+		if value < out {
+			out = value
+		}
+	}
+	return out
+}
+
+// knownLinterNames returns the names of the default linters and those
+// configured in config.
+func knownLinterNames(config *Config) []string {
+	out := []string{}
+	for name := range defaultLinters {
+		out = append(out, name)
+	}
+	for name := range config.Linters {
+		if _, ok := defaultLinters[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigUnknownKeys(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	var testcases = []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "typo.json",
+			content:  "{\n  \"Enable\": [\"golint\"],\n  \"Serverity\": {\"golint\": \"error\"}\n}\n",
+			expected: `{dir}/typo.json:3:3: unknown key "Serverity", did you mean "Severity"?`,
+		},
+		{
+			name:    "nested.json",
+			content: `{"ExcludeRules": [{"Linter": ["golint"]}], "Linters": {"x": {"Comand": "x"}}, "Bogus": 1}`,
+			expected: `{dir}/nested.json:1:20: unknown key "Linter", did you mean "Linters"?` + "\n" +
+				`{dir}/nested.json:1:62: unknown key "Comand", did you mean "Command"?` + "\n" +
+				`{dir}/nested.json:1:79: unknown key "Bogus"`,
+		},
+		{
+			name:     "override.json",
+			content:  `{"Overrides": [{"Paths": ["a"], "Config": {"Cyclomatic": 5}}]}`,
+			expected: `{dir}/override.json:1:44: unknown key "Cyclomatic"`,
+		},
+		{
+			name:     "typo.yaml",
+			content:  "Enable: [golint]\nExclude: [foo]\nDeadLine: 5s\nmessageoverrid: {}\n",
+			expected: `{dir}/typo.yaml:4:1: unknown key "messageoverrid", did you mean "MessageOverride"?`,
+		},
+		{
+			name:     "typo.toml",
+			content:  "Fast = true\n\n[[ExcludeRules]]\nPaths = \"*.go\"\n",
+			expected: `{dir}/typo.toml:4:1: unknown key "Paths", did you mean "Path"?`,
+		},
+	}
+	for _, testcase := range testcases {
+		path := filepath.Join(tmpdir, testcase.name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(testcase.content), 0644))
+		err := newConfigLoader(&Config{}).load(path)
+		require.Error(t, err, testcase.name)
+		expected := strings.Replace(testcase.expected, "{dir}", tmpdir, -1)
+		assert.Equal(t, expected, err.Error(), testcase.name)
+	}
+}
+
+func TestLoadConfigTypeErrorPosition(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(tmpdir, "config.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{\n  \"Cyclo\": \"high\"\n}\n"), 0644))
+	err := newConfigLoader(&Config{}).load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path+":2:18: ")
+}
+
+func TestSuggestName(t *testing.T) {
+	candidates := []string{"Severity", "Sort", "Skip", "golint", "gofmt"}
+	assert.Equal(t, "Severity", suggestName("serverity", candidates))
+	assert.Equal(t, "golint", suggestName("golnt", candidates))
+	assert.Equal(t, "Skip", suggestName("Skpi", candidates))
+	assert.Equal(t, "", suggestName("unrelated", candidates))
+}
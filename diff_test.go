@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := []string{}
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, string(rune('a'+i-1)))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+	lines[1] = "B"
+	lines[18] = "S"
+	lines = append(lines[:10], append([]string{"inserted"}, lines[10:]...)...)
+	fixed := strings.Join(lines, "\n")
+
+	expected := `--- a/file.go
++++ b/file.go
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,6 +8,7 @@
+ h
+ i
+ j
++inserted
+ k
+ l
+ m
+@@ -16,5 +17,5 @@
+ p
+ q
+ r
+-s
+-t
++S
++t
+\ No newline at end of file
+`
+	assert.Equal(t, expected, unifiedDiff("file.go", []byte(original), []byte(fixed)))
+	assert.Equal(t, "", unifiedDiff("file.go", []byte(original), []byte(original)))
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	expected := "--- a/file.go\n+++ b/file.go\n@@ -0,0 +1,1 @@\n+package foo\n"
+	assert.Equal(t, expected, unifiedDiff("file.go", nil, []byte("package foo\n")))
+}
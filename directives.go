@@ -5,6 +5,9 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -52,28 +55,52 @@ func (ir ignoredRanges) Swap(i, j int)      { ir[i], ir[j] = ir[j], ir[i] }
 func (ir ignoredRanges) Less(i, j int) bool { return ir[i].end < ir[j].end }
 
 type directiveParser struct {
-	lock  sync.Mutex
-	files map[string]ignoredRanges
-	fset  *token.FileSet
+	lock      sync.Mutex
+	files     map[string]ignoredRanges
+	generated map[string]bool
+	fset      *token.FileSet
+	// If true, issues in generated files are ignored, unless reported by one
+	// of reportGenerated.
+	skipGenerated   bool
+	reportGenerated map[string]bool
 }
 
 func newDirectiveParser() *directiveParser {
 	return &directiveParser{
-		files: map[string]ignoredRanges{},
-		fset:  token.NewFileSet(),
+		files:     map[string]ignoredRanges{},
+		generated: map[string]bool{},
+		fset:      token.NewFileSet(),
 	}
 }
 
-// IsIgnored returns true if the given linter issue is ignored by a linter directive.
+// SkipGenerated ignores issues in generated files, except for those reported
+// by linters with ReportGenerated set.
+func (d *directiveParser) SkipGenerated(linters map[string]*Linter) {
+	d.skipGenerated = true
+	d.reportGenerated = map[string]bool{}
+	for name, linter := range linters {
+		if linter.ReportGenerated {
+			d.reportGenerated[name] = true
+		}
+	}
+}
+
+// IsIgnored returns true if the given linter issue is ignored by a linter
+// directive, or because it is in a generated file.
 func (d *directiveParser) IsIgnored(issue *Issue) bool {
 	d.lock.Lock()
 	ranges, ok := d.files[issue.Path]
 	if !ok {
-		ranges = d.parseFile(issue.Path)
+		ranges, d.generated[issue.Path] = d.parseFile(issue.Path)
 		sort.Sort(ranges)
 		d.files[issue.Path] = ranges
 	}
+	generated := d.generated[issue.Path]
 	d.lock.Unlock()
+	if generated && d.skipGenerated && !d.reportsGenerated(issue) {
+		debug("ignoring issue in generated file %s", issue)
+		return true
+	}
 	for _, r := range ranges {
 		if r.matches(issue) {
 			debug("nolint: matched %s to issue %s", r, issue)
@@ -84,6 +111,17 @@ func (d *directiveParser) IsIgnored(issue *Issue) bool {
 	return false
 }
 
+// reportsGenerated returns true if any of the linters that reported issue,
+// which may have been aggregated, report issues in generated files.
+func (d *directiveParser) reportsGenerated(issue *Issue) bool {
+	for _, linter := range strings.Split(issue.Linter, ", ") {
+		if d.reportGenerated[linter] {
+			return true
+		}
+	}
+	return false
+}
+
 // Unmatched returns all the ranges which were never used to ignore an issue
 func (d *directiveParser) Unmatched() map[string]ignoredRanges {
 	unmatched := map[string]ignoredRanges{}
@@ -106,9 +144,10 @@ func (d *directiveParser) LoadFiles(paths []string) error {
 		return err
 	}
 	for _, filename := range filenames {
-		ranges := d.parseFile(filename)
+		ranges, generated := d.parseFile(filename)
 		sort.Sort(ranges)
 		d.files[filename] = ranges
+		d.generated[filename] = generated
 	}
 	return nil
 }
@@ -143,19 +182,120 @@ func (a *rangeExpander) Visit(node ast.Node) ast.Visitor {
 	return a
 }
 
-func (d *directiveParser) parseFile(path string) ignoredRanges {
+type directiveCacheEntry struct {
+	size      int64
+	modTime   time.Time
+	markers   string
+	ranges    ignoredRanges
+	generated bool
+}
+
+// directiveCache holds the directives parsed from each file for the lifetime of
+// the process, so that long-running modes such as --watch and serve only
+// re-parse files which have changed.
+type directiveCache struct {
+	lock    sync.Mutex
+	entries map[string]*directiveCacheEntry
+}
+
+var parsedDirectives = &directiveCache{entries: map[string]*directiveCacheEntry{}}
+
+// get returns a copy of the cached ranges for path, and whether it is
+// generated, if path is unchanged and was parsed with the same generated
+// markers.
+func (c *directiveCache) get(path string, info os.FileInfo, markers []string) (ignoredRanges, bool, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || entry.size != info.Size() || !entry.modTime.Equal(info.ModTime()) || entry.markers != strings.Join(markers, "\x00") {
+		return nil, false, false
+	}
+	return entry.ranges.copy(), entry.generated, true
+}
+
+func (c *directiveCache) put(path string, info os.FileInfo, markers []string, ranges ignoredRanges, generated bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries[path] = &directiveCacheEntry{
+		size:      info.Size(),
+		modTime:   info.ModTime(),
+		markers:   strings.Join(markers, "\x00"),
+		ranges:    ranges.copy(),
+		generated: generated,
+	}
+}
+
+// copy returns a deep copy of the ranges, with none of them matched.
+func (ir ignoredRanges) copy() ignoredRanges {
+	out := make(ignoredRanges, 0, len(ir))
+	for _, r := range ir {
+		c := *r
+		c.matched = false
+		out = append(out, &c)
+	}
+	return out
+}
+
+// parseFile returns the ranges ignored by directives in path, and whether it
+// is a generated file.
+func (d *directiveParser) parseFile(path string) (ignoredRanges, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	info, err := os.Stat(path)
+	if err == nil {
+		if ranges, generated, ok := parsedDirectives.get(abs, info, config.GeneratedMarkers); ok {
+			debug("nolint: using cached directives for %s", path)
+			return ranges, generated
+		}
+	}
+	ranges, generated := d.parseFileUncached(path)
+	if info != nil {
+		parsedDirectives.put(abs, info, config.GeneratedMarkers, ranges, generated)
+	}
+	return ranges, generated
+}
+
+func (d *directiveParser) parseFileUncached(path string) (ignoredRanges, bool) {
 	start := time.Now()
 	debug("nolint: parsing %s for directives", path)
 	file, err := parser.ParseFile(d.fset, path, nil, parser.ParseComments)
 	if err != nil {
 		debug("nolint: failed to parse %q: %s", path, err)
-		return nil
+		return nil, false
 	}
 	ranges := extractCommentGroupRange(d.fset, file.Comments...)
 	visitor := &rangeExpander{fset: d.fset, ranges: ranges}
 	ast.Walk(visitor, file)
+	generated := isGeneratedFile(file, config.GeneratedMarkers)
 	debug("nolint: parsing %s took %s", path, time.Since(start))
-	return visitor.ranges
+	return visitor.ranges, generated
+}
+
+// The comment marking a file as generated, by the convention described at
+// https://golang.org/s/generatedcode.
+var generatedCodeComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGeneratedFile returns true if a comment before the package clause of file
+// marks it as generated, by convention or by containing any of markers.
+func isGeneratedFile(file *ast.File, markers []string) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if generatedCodeComment.MatchString(comment.Text) {
+				return true
+			}
+			for _, marker := range markers {
+				if strings.Contains(comment.Text, marker) {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 func extractCommentGroupRange(fset *token.FileSet, comments ...*ast.CommentGroup) (ranges ignoredRanges) {
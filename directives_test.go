@@ -1,9 +1,13 @@
 package main
 
 import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIgnoreRangeMatch(t *testing.T) {
@@ -40,3 +44,78 @@ func TestIgnoreRangeMatch(t *testing.T) {
 		assert.Equal(t, testcase.expected, ir.matches(&testcase.issue), testcase.doc)
 	}
 }
+
+func TestIsGeneratedFile(t *testing.T) {
+	var testcases = []struct {
+		doc      string
+		source   string
+		markers  []string
+		expected bool
+	}{
+		{
+			doc:      "standard comment",
+			source:   "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage api\n",
+			expected: true,
+		},
+		{
+			doc:    "standard comment after package clause",
+			source: "package api\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n",
+		},
+		{
+			doc:    "not a line comment",
+			source: "/* Code generated by hand. DO NOT EDIT. */\npackage api\n",
+		},
+		{
+			doc:      "marker",
+			source:   "// This file was autogenerated by go-bindata.\n\npackage api\n",
+			markers:  []string{"autogenerated by"},
+			expected: true,
+		},
+		{
+			doc:    "not generated",
+			source: "// Package api serves the API.\npackage api\n",
+		},
+	}
+
+	for _, testcase := range testcases {
+		file, err := parser.ParseFile(token.NewFileSet(), "api.go", testcase.source, parser.ParseComments)
+		require.NoError(t, err)
+		assert.Equal(t, testcase.expected, isGeneratedFile(file, testcase.markers), testcase.doc)
+	}
+}
+
+func TestDirectiveParserSkipsGenerated(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(tmpdir, "mock.go"), "// Code generated by MockGen. DO NOT EDIT.\n\npackage foo\n")
+
+	linters := map[string]*Linter{
+		"golint": {Name: "golint"},
+		"gotype": {Name: "gotype", LinterConfig: LinterConfig{ReportGenerated: true}},
+	}
+	directives := newDirectiveParser()
+	assert.False(t, directives.IsIgnored(&Issue{Linter: "golint", Path: "mock.go", Line: 3}))
+	directives.SkipGenerated(linters)
+	assert.True(t, directives.IsIgnored(&Issue{Linter: "golint", Path: "mock.go", Line: 3}))
+	assert.False(t, directives.IsIgnored(&Issue{Linter: "gotype", Path: "mock.go", Line: 3}))
+	assert.False(t, directives.IsIgnored(&Issue{Linter: "golint, gotype", Path: "mock.go", Line: 3}))
+}
+
+func TestDirectiveCacheRespectsGeneratedMarkers(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(tmpdir, "gen.go"), "// Generated by tool.\n\npackage foo\n")
+	defer func(markers []string) { config.GeneratedMarkers = markers }(config.GeneratedMarkers)
+	linters := map[string]*Linter{"golint": {Name: "golint"}}
+	issue := &Issue{Linter: "golint", Path: "gen.go", Line: 3}
+
+	config.GeneratedMarkers = nil
+	directives := newDirectiveParser()
+	directives.SkipGenerated(linters)
+	assert.False(t, directives.IsIgnored(issue))
+
+	config.GeneratedMarkers = []string{"Generated by tool"}
+	directives = newDirectiveParser()
+	directives.SkipGenerated(linters)
+	assert.True(t, directives.IsIgnored(issue))
+}
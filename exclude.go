@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ExcludeRule suppresses issues matching all of its non-empty criteria.
+type ExcludeRule struct {
+	// Names of linters the rule applies to. Any linter if empty.
+	Linters []string
+	// Glob matched against the issue path, relative to the working directory
+	// and with forward slashes. "**" matches any number of directories.
+	Path string
+	// Regular expression matched against the issue message.
+	Message string
+	// Severity of the issues to suppress.
+	Severity string
+	// Why the issues are suppressed. Informational only.
+	Reason string
+}
+
+func (r *ExcludeRule) String() string {
+	criteria := []string{}
+	if len(r.Linters) > 0 {
+		criteria = append(criteria, "linters="+strings.Join(r.Linters, ","))
+	}
+	if r.Path != "" {
+		criteria = append(criteria, fmt.Sprintf("path=%q", r.Path))
+	}
+	if r.Message != "" {
+		criteria = append(criteria, fmt.Sprintf("message=%q", r.Message))
+	}
+	if r.Severity != "" {
+		criteria = append(criteria, "severity="+r.Severity)
+	}
+	out := strings.Join(criteria, " ")
+	if r.Reason != "" {
+		out += fmt.Sprintf(" (%s)", r.Reason)
+	}
+	return out
+}
+
+type compiledExcludeRule struct {
+	*ExcludeRule
+	linters map[string]bool
+	message *regexp.Regexp
+}
+
+func compileExcludeRules(rules []*ExcludeRule) ([]*compiledExcludeRule, error) {
+	out := make([]*compiledExcludeRule, 0, len(rules))
+	for i, rule := range rules {
+		compiled, err := compileExcludeRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("exclude rule %d: %s", i+1, err)
+		}
+		out = append(out, compiled)
+	}
+	return out, nil
+}
+
+func compileExcludeRule(rule *ExcludeRule) (*compiledExcludeRule, error) {
+	if len(rule.Linters) == 0 && rule.Path == "" && rule.Message == "" && rule.Severity == "" {
+		return nil, errors.New("at least one of Linters, Path, Message or Severity is required")
+	}
+	out := &compiledExcludeRule{ExcludeRule: rule, linters: map[string]bool{}}
+	for _, linter := range rule.Linters {
+		out.linters[linter] = true
+	}
+	if rule.Path != "" {
+		if _, err := path.Match(rule.Path, ""); err != nil {
+			return nil, fmt.Errorf("invalid path glob %q: %s", rule.Path, err)
+		}
+	}
+	if rule.Message != "" {
+		re, err := regexp.Compile(rule.Message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message regexp %q: %s", rule.Message, err)
+		}
+		out.message = re
+	}
+	return out, nil
+}
+
+func (r *compiledExcludeRule) matches(issue *Issue) bool {
+	if len(r.linters) > 0 && !r.linters[issue.Linter] {
+		return false
+	}
+	if r.Severity != "" && Severity(r.Severity) != issue.Severity {
+		return false
+	}
+	if r.Path != "" && !matchPathGlob(r.Path, filepath.ToSlash(filepath.Clean(issue.Path))) {
+		return false
+	}
+	if r.message != nil && !r.message.MatchString(issue.Message) {
+		return false
+	}
+	return true
+}
+
+// matchPathGlob matches a slash separated path against a glob, where "**"
+// matches zero or more path elements.
+func matchPathGlob(pattern, name string) bool {
+	return matchGlobElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobElements(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchExcludeRules returns the index of the first rule matching issue, or -1.
+func matchExcludeRules(rules []*compiledExcludeRule, issue *Issue) int {
+	for i, rule := range rules {
+		if rule.matches(issue) {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterIssuesViaExcludeRules suppresses issues matching any rule, and reports
+// how many issues each rule suppressed once all issues have been processed.
+func filterIssuesViaExcludeRules(rules []*compiledExcludeRule, issues chan *Issue) chan *Issue {
+	out := make(chan *Issue, 1000000)
+	go func() {
+		suppressed := make([]int, len(rules))
+		for issue := range issues {
+			if i := matchExcludeRules(rules, issue); i != -1 {
+				suppressed[i]++
+				continue
+			}
+			out <- issue
+		}
+		for i, rule := range rules {
+			debug("exclude rule %d suppressed %d issues: %s", i+1, suppressed[i], rule)
+		}
+		close(out)
+	}()
+	return out
+}
+
+func maybeFilterIssuesViaExcludeRules(issues chan *Issue) chan *Issue {
+	if len(config.excludeRules) == 0 {
+		return issues
+	}
+	return filterIssuesViaExcludeRules(config.excludeRules, issues)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	var testcases = []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "*.go", path: "file.go", expected: true},
+		{pattern: "*.go", path: "pkg/file.go", expected: false},
+		{pattern: "**/*_test.go", path: "file_test.go", expected: true},
+		{pattern: "**/*_test.go", path: "a/b/file_test.go", expected: true},
+		{pattern: "**/*_test.go", path: "a/b/file.go", expected: false},
+		{pattern: "vendor/**", path: "vendor/a/b.go", expected: true},
+		{pattern: "pkg/*/gen.go", path: "pkg/a/gen.go", expected: true},
+		{pattern: "pkg/*/gen.go", path: "pkg/a/b/gen.go", expected: false},
+	}
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, matchPathGlob(testcase.pattern, testcase.path), "%s %s", testcase.pattern, testcase.path)
+	}
+}
+
+func TestCompileExcludeRules(t *testing.T) {
+	_, err := compileExcludeRules([]*ExcludeRule{{Reason: "no criteria"}})
+	assert.Error(t, err)
+	_, err = compileExcludeRules([]*ExcludeRule{{Message: "("}})
+	assert.Error(t, err)
+	_, err = compileExcludeRules([]*ExcludeRule{{Path: "["}})
+	assert.Error(t, err)
+}
+
+func TestFilterIssuesViaExcludeRules(t *testing.T) {
+	rules, err := compileExcludeRules([]*ExcludeRule{
+		{Linters: []string{"errcheck"}, Path: "**/*_test.go"},
+		{Message: "^exported .* should have comment", Severity: "warning"},
+	})
+	require.NoError(t, err)
+
+	issues := make(chan *Issue, 10)
+	issues <- &Issue{Linter: "errcheck", Severity: Warning, Path: "pkg/file_test.go", Message: "error return value not checked"}
+	issues <- &Issue{Linter: "errcheck", Severity: Warning, Path: "pkg/file.go", Message: "error return value not checked"}
+	issues <- &Issue{Linter: "golint", Severity: Warning, Path: "pkg/file.go", Message: "exported type Foo should have comment or be unexported"}
+	issues <- &Issue{Linter: "golint", Severity: Error, Path: "pkg/file.go", Message: "exported type Foo should have comment or be unexported"}
+	close(issues)
+
+	actual := []*Issue{}
+	for issue := range filterIssuesViaExcludeRules(rules, issues) {
+		actual = append(actual, issue)
+	}
+	require.Len(t, actual, 2)
+	assert.Equal(t, "errcheck", actual[0].Linter)
+	assert.Equal(t, "pkg/file.go", actual[0].Path)
+	assert.Equal(t, Error, actual[1].Severity)
+}
@@ -8,13 +8,14 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/google/shlex"
-	kingpin "gopkg.in/alecthomas/kingpin.v3-unstable"
 )
 
 type Vars map[string]string
@@ -42,18 +43,24 @@ func (v Vars) Replace(s string) string {
 
 type linterState struct {
 	*Linter
-	issues   chan *Issue
-	vars     Vars
-	exclude  *regexp.Regexp
-	include  *regexp.Regexp
-	deadline <-chan time.Time
+	issues  chan *Issue
+	vars    Vars
+	exclude *regexp.Regexp
+	include *regexp.Regexp
+	// Closed once timeout has elapsed since the linter was scheduled.
+	deadline <-chan struct{}
+	timeout  time.Duration
+	// Closed when the deadline for the whole run expires.
+	totalDeadline <-chan struct{}
+	cancel        <-chan struct{}
+	cache         *resultCache
+	buildContext  *buildContext
+	// The configuration of the group of paths being linted, or nil for the
+	// global configuration.
+	config *Config
 }
 
-func (l *linterState) Partitions(paths []string) ([][]string, error) {
-	cmdArgs, err := parseCommand(l.command())
-	if err != nil {
-		return nil, err
-	}
+func (l *linterState) Partitions(cmdArgs []string, paths []string) ([][]string, error) {
 	parts, err := l.Linter.PartitionStrategy(cmdArgs, paths)
 	if err != nil {
 		return nil, err
@@ -65,76 +72,239 @@ func (l *linterState) command() string {
 	return l.vars.Replace(l.Command)
 }
 
-func runLinters(linters map[string]*Linter, paths []string, concurrency int, exclude, include *regexp.Regexp) (chan *Issue, chan error) {
-	errch := make(chan error, len(linters))
+// env returns the environment variables the linter is run with, in addition
+// to those of gometalinter.
+func (l *linterState) env() []string {
+	if l.buildContext == nil {
+		return nil
+	}
+	return l.buildContext.env()
+}
+
+// linterExecution records a single invocation of a linter over one partition.
+type linterExecution struct {
+	linter string
+	paths  []string
+	err    error
+}
+
+// executionLog records every linter invocation made by runLinters, so that
+// output formats can report what ran as well as the issues that were found.
+type executionLog struct {
+	lock       sync.Mutex
+	executions []*linterExecution
+}
+
+func (e *executionLog) add(linter string, paths []string, err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.executions = append(e.executions, &linterExecution{
+		linter: linter,
+		paths:  paths,
+		err:    err,
+	})
+}
+
+// timeouts returns the first timeout of each linter that was terminated
+// because a deadline expired, ordered by linter name.
+func (e *executionLog) timeouts() []*linterTimeout {
+	seen := map[string]bool{}
+	out := []*linterTimeout{}
+	for _, execution := range e.Executions() {
+		if timeout, ok := execution.err.(*linterTimeout); ok && !seen[timeout.Linter] {
+			seen[timeout.Linter] = true
+			out = append(out, timeout)
+		}
+	}
+	sort.Sort(linterTimeouts(out))
+	return out
+}
+
+type linterTimeouts []*linterTimeout
+
+func (l linterTimeouts) Len() int           { return len(l) }
+func (l linterTimeouts) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l linterTimeouts) Less(i, j int) bool { return l[i].Linter < l[j].Linter }
+
+// Executions returns all recorded linter invocations. It is only complete once
+// the issue channel returned by runLinters has been drained.
+func (e *executionLog) Executions() []*linterExecution {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]*linterExecution{}, e.executions...)
+}
+
+// errorLog collects the errors reported while running linters. Errors are
+// collected rather than sent as they occur, as the error channel is only
+// drained once all issues have been read.
+type errorLog struct {
+	lock   sync.Mutex
+	errors []error
+}
+
+func (e *errorLog) add(err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.errors = append(e.errors, err)
+}
+
+// runLinters runs linters over paths. Linters still running when cancel is
+// closed are killed; cancel may be nil.
+func runLinters(linters map[string]*Linter, paths []string, concurrency int, exclude, include *regexp.Regexp, cancel <-chan struct{}) (chan *Issue, chan error, *executionLog) {
+	groups, groupErr := groupPaths(linters, paths)
+	allLinters := map[string]*Linter{}
+	for name, linter := range linters {
+		allLinters[name] = linter
+	}
+	for _, group := range groups {
+		for name, linter := range group.linters {
+			allLinters[name] = linter
+		}
+	}
+	errch := make(chan error)
+	errs := &errorLog{}
+	if groupErr != nil {
+		errs.add(groupErr)
+	}
+	executions := &executionLog{}
 	concurrencych := make(chan bool, concurrency)
 	incomingIssues := make(chan *Issue, 1000000)
 
 	directiveParser := newDirectiveParser()
+	if config.SkipGenerated {
+		directiveParser.SkipGenerated(allLinters)
+	}
 	if config.WarnUnmatchedDirective {
 		directiveParser.LoadFiles(paths)
 	}
 
-	processedIssues := maybeSortIssues(filterIssuesViaDirectives(
-		directiveParser, maybeAggregateIssues(incomingIssues)))
+	processedIssues := maybeSortIssues(maybeFilterNewIssues(allLinters, maybeFilterIssuesViaBaseline(paths,
+		filterIssuesViaDirectives(directiveParser, maybeAggregateIssues(
+			maybeFilterIssuesViaExcludeRules(maybeFilterSkippedFiles(maybeDedupeIssues(maybeMergeBuildContextIssues(incomingIssues)))))))))
 
-	vars := Vars{
-		"duplthreshold":    fmt.Sprintf("%d", config.DuplThreshold),
-		"mincyclo":         fmt.Sprintf("%d", config.Cyclo),
-		"maxlinelength":    fmt.Sprintf("%d", config.LineLength),
-		"min_confidence":   fmt.Sprintf("%f", config.MinConfidence),
-		"min_occurrences":  fmt.Sprintf("%d", config.MinOccurrences),
-		"min_const_length": fmt.Sprintf("%d", config.MinConstLength),
-		"tests":            "",
-		"not_tests":        "true",
-	}
-	if config.Test {
-		vars["tests"] = "true"
-		vars["not_tests"] = ""
+	var cache *resultCache
+	if config.Cache {
+		cache = newResultCache(config.CacheDir, int64(config.CacheSize)*1024*1024)
 	}
 
+	totalDeadline, stopTotalDeadline := closeAfter(config.TotalDeadline.Duration())
+	stopDeadlines := []func(){stopTotalDeadline}
+
 	wg := &sync.WaitGroup{}
 	id := 1
-	for _, linter := range linters {
-		deadline := time.After(config.Deadline.Duration())
-		state := &linterState{
-			Linter:   linter,
-			issues:   incomingIssues,
-			vars:     vars,
-			exclude:  exclude,
-			include:  include,
-			deadline: deadline,
+	for _, group := range groups {
+		if len(groups) > 1 {
+			debug("linting %s with %s configuration", strings.Join(group.paths, " "), group)
 		}
-
-		partitions, err := state.Partitions(paths)
+		contexts, err := buildContexts(group.config)
 		if err != nil {
-			errch <- err
+			errs.add(err)
 			continue
 		}
-		for _, args := range partitions {
-			wg.Add(1)
-			concurrencych <- true
-			// Call the goroutine with a copy of the args array so that the
-			// contents of the array are not modified by the next iteration of
-			// the above for loop
-			go func(id int, args []string) {
-				err := executeLinter(id, state, args)
+		for _, context := range contexts {
+			if context.matrix {
+				debug("linting under build context %s", context)
+			}
+			vars := linterVars(group.config)
+			setBuildTagVars(vars, context.Tags)
+			for _, linter := range group.linters {
+				timeout := group.config.Deadline.Duration()
+				if linter.Deadline != 0 {
+					timeout = linter.Deadline.Duration()
+				}
+				deadline, stopDeadline := closeAfter(timeout)
+				stopDeadlines = append(stopDeadlines, stopDeadline)
+				state := &linterState{
+					Linter:        linter,
+					issues:        incomingIssues,
+					vars:          vars,
+					exclude:       exclude,
+					include:       include,
+					deadline:      deadline,
+					timeout:       timeout,
+					totalDeadline: totalDeadline,
+					cancel:        cancel,
+					cache:         cache,
+					buildContext:  context,
+					config:        group.config,
+				}
+
+				cmdArgs, err := parseCommand(state.command())
+				if err != nil {
+					errs.add(err)
+					continue
+				}
+				partitions, err := state.Partitions(cmdArgs, group.paths)
 				if err != nil {
-					errch <- err
+					errs.add(err)
+					continue
 				}
-				<-concurrencych
-				wg.Done()
-			}(id, args)
-			id++
+				for _, args := range partitions {
+					wg.Add(1)
+					concurrencych <- true
+					// Call the goroutine with a copy of the args array so that the
+					// contents of the array are not modified by the next iteration of
+					// the above for loop
+					go func(id int, state *linterState, cmdArgs, args []string) {
+						err := executeLinter(id, state, args)
+						executions.add(state.Name, args[len(cmdArgs):], err)
+						if err != nil {
+							errs.add(err)
+						}
+						<-concurrencych
+						wg.Done()
+					}(id, state, cmdArgs, args)
+					id++
+				}
+			}
 		}
 	}
 
 	go func() {
 		wg.Wait()
+		for _, stop := range stopDeadlines {
+			stop()
+		}
+		if cache != nil {
+			if err := cache.evict(); err != nil {
+				warning("failed to evict cached results: %s", err)
+			}
+		}
 		close(incomingIssues)
+		for _, err := range errs.errors {
+			errch <- err
+		}
 		close(errch)
 	}()
-	return processedIssues, errch
+	return processedIssues, errch, executions
+}
+
+// linterVars returns the variables available to linter commands.
+func linterVars(config *Config) Vars {
+	vars := Vars{
+		"duplthreshold":    fmt.Sprintf("%d", config.DuplThreshold),
+		"mincyclo":         fmt.Sprintf("%d", config.Cyclo),
+		"maxlinelength":    fmt.Sprintf("%d", config.LineLength),
+		"min_confidence":   fmt.Sprintf("%f", config.MinConfidence),
+		"min_occurrences":  fmt.Sprintf("%d", config.MinOccurrences),
+		"min_const_length": fmt.Sprintf("%d", config.MinConstLength),
+		"tests":            "",
+		"not_tests":        "true",
+	}
+	setBuildTagVars(vars, splitBuildTags(config.Tags))
+	if config.Test {
+		vars["tests"] = "true"
+		vars["not_tests"] = ""
+	}
+	return vars
+}
+
+// setBuildTagVars sets {tags} to a comma separated list of tags, as accepted by
+// govet, and {tags_space} to a space separated list, as accepted by go test,
+// errcheck and the honnef.co/go/tools linters.
+func setBuildTagVars(vars Vars, tags []string) {
+	vars["tags"] = strings.Join(tags, ",")
+	vars["tags_space"] = strings.Join(tags, " ")
 }
 
 func executeLinter(id int, state *linterState, args []string) error {
@@ -142,44 +312,110 @@ func executeLinter(id int, state *linterState, args []string) error {
 		return fmt.Errorf("missing linter command")
 	}
 
+	select {
+	case <-state.cancel:
+		return fmt.Errorf("linter %s cancelled", state.Name)
+	case <-state.totalDeadline:
+		return &linterTimeout{Linter: state.Name, Timeout: config.TotalDeadline.Duration(), Total: true}
+	default:
+	}
+
 	start := time.Now()
 	dbg := namespacedDebug(fmt.Sprintf("[%s.%d]: ", state.Name, id))
+	key := ""
+	if state.cache != nil && (state.Cacheable == nil || *state.Cacheable) {
+		var ok bool
+		if key, ok = state.cache.key(state, args); ok {
+			if out, ok := state.cache.get(key); ok {
+				dbg("using cached output for %s", strings.Join(args, " "))
+				_, err := processOutput(dbg, state, out)
+				return err
+			}
+		}
+	}
 	dbg("executing %s", strings.Join(args, " "))
-	buf := bytes.NewBuffer(nil)
+	stdout := bytes.NewBuffer(nil)
+	stderr := stdout
+	if state.Stream == "stdout" || state.Stream == "stderr" {
+		stderr = bytes.NewBuffer(nil)
+	}
 	command := args[0]
 	cmd := exec.Command(command, args[1:]...) // nolint: gas
-	cmd.Stdout = buf
-	cmd.Stderr = buf
-	err := cmd.Start()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if env := state.env(); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	startProcessGroup(cmd)
+	started, err := runningLinters.start(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to execute linter %s: %s", command, err)
 	}
+	if !started {
+		return fmt.Errorf("linter %s interrupted", state.Name)
+	}
+	defer runningLinters.done()
 
-	done := make(chan bool)
+	done := make(chan error, 1)
 	go func() {
-		err = cmd.Wait()
-		done <- true
+		done <- cmd.Wait()
 	}()
 
-	// Wait for process to complete or deadline to expire.
+	// Wait for process to complete, a deadline to expire or the run to be
+	// cancelled or interrupted.
+	var timeout *linterTimeout
+	interrupted := false
 	select {
-	case <-done:
+	case err = <-done:
 
 	case <-state.deadline:
-		err = fmt.Errorf("deadline exceeded by linter %s (try increasing --deadline)",
-			state.Name)
-		kerr := cmd.Process.Kill()
-		if kerr != nil {
-			warning("failed to kill %s: %s", state.Name, kerr)
-		}
-		return err
+		timeout = &linterTimeout{Linter: state.Name, Timeout: state.timeout}
+		err = terminateLinter(state, cmd, done)
+
+	case <-state.totalDeadline:
+		timeout = &linterTimeout{Linter: state.Name, Timeout: config.TotalDeadline.Duration(), Total: true}
+		err = terminateLinter(state, cmd, done)
+
+	case <-state.cancel:
+		terminateLinter(state, cmd, done)
+		return fmt.Errorf("linter %s cancelled", state.Name)
+
+	case <-runningLinters.interrupted:
+		interrupted = true
+		err = terminateLinter(state, cmd, done)
 	}
 
+	out, unparsed := stdout.Bytes(), []byte(nil)
+	switch state.Stream {
+	case "stdout":
+		unparsed = stderr.Bytes()
+	case "stderr":
+		out, unparsed = stderr.Bytes(), stdout.Bytes()
+	}
 	if err != nil {
-		dbg("warning: %s returned %s: %s", command, err, buf.String())
+		dbg("warning: %s returned %s: %s%s", command, err, out, unparsed)
 	}
 
-	processOutput(dbg, state, buf.Bytes())
+	hits, parseErr := processOutput(dbg, state, out)
+	// Report what the linter found before it was terminated, but don't cache
+	// it.
+	if timeout != nil {
+		return timeout
+	}
+	if interrupted {
+		return fmt.Errorf("linter %s interrupted", state.Name)
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+	if failure := newLinterError(state, err, hits, out, unparsed); failure != nil {
+		return failure
+	}
+	if key != "" {
+		if err := state.cache.put(key, out); err != nil {
+			warning("failed to cache output of %s: %s", state.Name, err)
+		}
+	}
 	elapsed := time.Since(start)
 	dbg("%s linter took %s", state.Name, elapsed)
 	return nil
@@ -200,8 +436,156 @@ func parseCommand(command string) ([]string, error) {
 	return append([]string{exe}, args[1:]...), nil
 }
 
+// linterProcesses tracks running linters, so that they can all be terminated
+// when gometalinter is interrupted.
+type linterProcesses struct {
+	lock        sync.Mutex
+	running     sync.WaitGroup
+	interrupted chan struct{}
+}
+
+var runningLinters = &linterProcesses{interrupted: make(chan struct{})}
+
+// start starts cmd, unless linters have been interrupted. done must be called
+// once a started command has exited.
+func (p *linterProcesses) start(cmd *exec.Cmd) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	select {
+	case <-p.interrupted:
+		return false, nil
+	default:
+	}
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	p.running.Add(1)
+	return true, nil
+}
+
+func (p *linterProcesses) done() {
+	p.running.Done()
+}
+
+// interrupt terminates all running linters, prevents any more from starting,
+// and waits for the running linters to exit.
+func (p *linterProcesses) interrupt() {
+	p.lock.Lock()
+	select {
+	case <-p.interrupted:
+	default:
+		close(p.interrupted)
+	}
+	p.lock.Unlock()
+	p.running.Wait()
+}
+
+// Time allowed for a linter to exit after being asked to terminate, before it
+// is killed.
+var terminateGracePeriod = 5 * time.Second
+
+// terminateLinter asks the process group of a linter to terminate, killing it
+// if it has not exited within terminateGracePeriod, and returns the result of
+// waiting for the linter to exit.
+func terminateLinter(state *linterState, cmd *exec.Cmd, done <-chan error) error {
+	if err := signalProcessGroup(cmd, false); err != nil {
+		warning("failed to terminate %s: %s", state.Name, err)
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(terminateGracePeriod):
+	}
+	if err := signalProcessGroup(cmd, true); err != nil {
+		warning("failed to kill %s: %s", state.Name, err)
+	}
+	return <-done
+}
+
+// linterTimeout is reported when a linter is terminated because a deadline
+// expired.
+type linterTimeout struct {
+	Linter  string
+	Timeout time.Duration
+	// True if the deadline was for the whole run, rather than the linter.
+	Total bool
+}
+
+func (e *linterTimeout) Error() string {
+	if e.Total {
+		return fmt.Sprintf("linter %s did not complete within the total deadline of %s (try increasing --total-deadline)", e.Linter, e.Timeout)
+	}
+	return fmt.Sprintf("deadline of %s exceeded by linter %s (try increasing --deadline)", e.Timeout, e.Linter)
+}
+
+// closeAfter returns a channel that is closed once d has elapsed, and a
+// function to stop the timer. If d is not positive the channel is never
+// closed.
+func closeAfter(d time.Duration) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+	if d <= 0 {
+		return ch, func() {}
+	}
+	timer := time.AfterFunc(d, func() { close(ch) })
+	return ch, func() { timer.Stop() }
+}
+
+// linterError is reported when a linter exits with an error, rather than
+// (or as well as) reporting issues.
+type linterError struct {
+	Linter   string
+	ExitCode int
+	// Output of the linter that was not parsed for issues, or all of its
+	// output if it reported no issues.
+	Output string
+}
+
+func (e *linterError) Error() string {
+	if e.Output == "" {
+		return fmt.Sprintf("linter %s failed with exit status %d", e.Linter, e.ExitCode)
+	}
+	return fmt.Sprintf("linter %s failed with exit status %d: %s", e.Linter, e.ExitCode, e.Output)
+}
+
+// newLinterError returns an error describing the failure of a linter that
+// exited with err after reporting hits issues, or nil if it did not fail.
+// A linter fails if it exits with a non-zero status and writes to a stream
+// that is not parsed for issues, or, with ReportExitErrors, if it exits with a
+// non-zero status and reports no issues.
+func newLinterError(state *linterState, err error, hits int, out, unparsed []byte) error {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return nil
+	}
+	failure := &linterError{Linter: state.Name, ExitCode: exitStatus(exitErr)}
+	switch {
+	case len(bytes.TrimSpace(unparsed)) > 0:
+		failure.Output = string(bytes.TrimSpace(unparsed))
+	case state.ReportExitErrors && hits == 0:
+		failure.Output = string(bytes.TrimSpace(out))
+	default:
+		return nil
+	}
+	return failure
+}
+
+// exitStatus returns the exit status of a process, or -1 if it is unknown.
+func exitStatus(err *exec.ExitError) int {
+	if status, ok := err.Sys().(syscall.WaitStatus); ok {
+		return status.ExitStatus()
+	}
+	return -1
+}
+
+// processOutput parses issues from the output of a linter, returning the
+// number found. Errors are returned rather than being fatal, as linters are
+// run in their own goroutines, including by the serve daemon.
 // nolint: gocyclo
-func processOutput(dbg debugFunction, state *linterState, out []byte) {
+func processOutput(dbg debugFunction, state *linterState, out []byte) (int, error) {
+	if state.Format == "json" {
+		return processJSONOutput(dbg, state, out)
+	}
+
 	re := state.regex
 	all := re.FindAllSubmatchIndex(out, -1)
 	dbg("%s hits %d: %s", state.Name, len(all), state.Pattern)
@@ -211,9 +595,6 @@ func processOutput(dbg debugFunction, state *linterState, out []byte) {
 		warning("failed to get working directory %s", err)
 	}
 
-	// Create a local copy of vars so they can be modified by the linter output
-	vars := state.vars.Copy()
-
 	for _, indices := range all {
 		group := [][]byte{}
 		for i := 0; i < len(indices); i += 2 {
@@ -225,7 +606,12 @@ func processOutput(dbg debugFunction, state *linterState, out []byte) {
 		}
 
 		issue, err := NewIssue(state.Linter.Name, config.formatTemplate)
-		kingpin.FatalIfError(err, "Invalid output format")
+		if err != nil {
+			return 0, fmt.Errorf("invalid output format: %s", err)
+		}
+
+		// Create a local copy of vars so they can be modified by the linter output
+		vars := state.vars.Copy()
 
 		for i, name := range re.SubexpNames() {
 			if group[i] == nil {
@@ -235,42 +621,75 @@ func processOutput(dbg debugFunction, state *linterState, out []byte) {
 			if name != "" {
 				vars[name] = part
 			}
-			switch name {
-			case "path":
-				issue.Path = relativePath(cwd, part)
-
-			case "line":
-				n, err := strconv.ParseInt(part, 10, 32)
-				kingpin.FatalIfError(err, "line matched invalid integer")
-				issue.Line = int(n)
-
-			case "col":
-				n, err := strconv.ParseInt(part, 10, 32)
-				kingpin.FatalIfError(err, "col matched invalid integer")
-				issue.Col = int(n)
+			if err := setIssueField(state.Linter, issue, cwd, name, part); err != nil {
+				return 0, fmt.Errorf("%s: %s matched invalid integer: %s", state.Name, name, err)
+			}
+		}
+		emitIssue(state, issue, vars)
+	}
+	return len(all), nil
+}
 
-			case "message":
-				issue.Message = part
+// setIssueField sets the field of issue corresponding to a capture group or
+// JSON field name. Unrecognised names are ignored.
+func setIssueField(linter *Linter, issue *Issue, cwd, name, value string) error {
+	switch name {
+	case "path":
+		issue.Path = relativePath(cwd, value)
 
-			case "":
-			}
+	case "line":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
 		}
-		// TODO: set messageOveride and severity on the Linter instead of reading
-		// them directly from the static config
-		if m, ok := config.MessageOverride[state.Name]; ok {
-			issue.Message = vars.Replace(m)
+		issue.Line = int(n)
+
+	case "col":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
 		}
-		if sev, ok := config.Severity[state.Name]; ok {
+		issue.Col = int(n)
+
+	case "message":
+		issue.Message = value
+
+	case "severity":
+		issue.Severity = linter.severity(value)
+
+	case "rule":
+		issue.Rule = value
+	}
+	return nil
+}
+
+// emitIssue applies the message and severity overrides configured for the
+// paths being linted to issue, and sends it unless it is excluded. The
+// configured severity only applies to issues for which the linter did not
+// report a severity.
+func emitIssue(state *linterState, issue *Issue, vars Vars) {
+	cfg := state.config
+	if cfg == nil {
+		cfg = config
+	}
+	if m, ok := cfg.MessageOverride[state.Name]; ok {
+		issue.Message = vars.Replace(m)
+	}
+	if _, reported := vars["severity"]; !reported {
+		if sev, ok := cfg.Severity[state.Name]; ok {
 			issue.Severity = Severity(sev)
 		}
-		if state.exclude != nil && state.exclude.MatchString(issue.String()) {
-			continue
-		}
-		if state.include != nil && !state.include.MatchString(issue.String()) {
-			continue
-		}
-		state.issues <- issue
 	}
+	if state.exclude != nil && state.exclude.MatchString(issue.String()) {
+		return
+	}
+	if state.include != nil && !state.include.MatchString(issue.String()) {
+		return
+	}
+	if state.buildContext != nil && state.buildContext.matrix {
+		issue.BuildContexts = []string{state.buildContext.String()}
+	}
+	state.issues <- issue
 }
 
 func relativePath(root, path string) string {
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/shlex"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLinterStateCommand(t *testing.T) {
-	varsDefault := Vars{"tests": "", "not_tests": "true"}
-	varsWithTest := Vars{"tests": "true", "not_tests": ""}
+	varsDefault := Vars{"tests": "", "not_tests": "true", "tags": "", "tags_space": ""}
+	varsWithTest := Vars{"tests": "true", "not_tests": "", "tags": "", "tags_space": ""}
 
 	var testcases = []struct {
 		linter   string
@@ -18,12 +27,22 @@ func TestLinterStateCommand(t *testing.T) {
 		{
 			linter:   "errcheck",
 			vars:     varsWithTest,
-			expected: `errcheck -abspath `,
+			expected: `errcheck -abspath  `,
 		},
 		{
 			linter:   "errcheck",
 			vars:     varsDefault,
-			expected: `errcheck -abspath -ignoretests`,
+			expected: `errcheck -abspath -ignoretests `,
+		},
+		{
+			linter:   "errcheck",
+			vars:     Vars{"tests": "true", "not_tests": "", "tags": "integration", "tags_space": "integration"},
+			expected: `errcheck -abspath  -tags='integration'`,
+		},
+		{
+			linter:   "staticcheck",
+			vars:     Vars{"tags": "integration,slow", "tags_space": "integration slow"},
+			expected: `staticcheck -tags='integration slow'`,
 		},
 		{
 			linter:   "gotype",
@@ -55,6 +74,16 @@ func TestLinterStateCommand(t *testing.T) {
 			vars: varsWithTest,
 			expected: `unparam `,
 		},
+		{
+			linter:   "vet",
+			vars:     Vars{"tags": ""},
+			expected: `govet --no-recurse `,
+		},
+		{
+			linter:   "vet",
+			vars:     Vars{"tags": "integration,slow"},
+			expected: `govet --no-recurse -tags=integration,slow`,
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -65,3 +94,279 @@ func TestLinterStateCommand(t *testing.T) {
 		assert.Equal(t, testcase.expected, ls.command())
 	}
 }
+
+func TestBuildTagsArePassedAsOneArgument(t *testing.T) {
+	vars := Vars{}
+	setBuildTagVars(vars, []string{"integration", "slow"})
+	assert.Equal(t, Vars{"tags": "integration,slow", "tags_space": "integration slow"}, vars)
+
+	for _, name := range []string{"errcheck", "gosimple", "megacheck", "staticcheck", "unused", "test"} {
+		ls := linterState{Linter: getLinterByName(name, LinterConfig{}), vars: vars}
+		args, err := shlex.Split(ls.command())
+		require.NoError(t, err)
+		assert.Equal(t, "-tags=integration slow", args[len(args)-1], name)
+	}
+	ls := linterState{Linter: getLinterByName("gas", LinterConfig{}), vars: vars}
+	assert.False(t, strings.Contains(ls.command(), "-tags"))
+}
+
+func TestProcessOutputSeverityAndRule(t *testing.T) {
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	config.Severity["fake"] = "error"
+
+	linter, err := NewLinter("fake", LinterConfig{
+		Command:    "fake",
+		Pattern:    `^(?P<path>.*?\.go):(?P<line>\d+):(?:(?P<severity>\w+):(?P<rule>\w+):)?\s*(?P<message>.*)$`,
+		Severities: map[string]string{"MEDIUM": "error"},
+	})
+	require.NoError(t, err)
+	state := &linterState{Linter: linter, issues: make(chan *Issue, 10), vars: Vars{}}
+	output := "a.go:1:HIGH:G101: hardcoded credentials\nb.go:2:LOW:G104: errors unhandled\nc.go:3:MEDIUM:G201: sql\nd.go:4: plain\n"
+	processOutput(func(string, ...interface{}) {}, state, []byte(output))
+	close(state.issues)
+
+	actual := []string{}
+	for issue := range state.issues {
+		actual = append(actual, string(issue.Severity)+" "+issue.Rule+" "+issue.Message)
+	}
+	assert.Equal(t, []string{
+		"error G101 hardcoded credentials",
+		"warning G104 errors unhandled",
+		"error G201 sql",
+		"error  plain",
+	}, actual)
+}
+
+func TestExecuteLinterStreams(t *testing.T) {
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho a.go:1: found it\necho build failed >&2\nexit $1\n"), 0755)
+	require.NoError(t, err)
+
+	var testcases = []struct {
+		conf     LinterConfig
+		exitCode string
+		issues   int
+		err      string
+	}{
+		{conf: LinterConfig{}, exitCode: "2", issues: 1},
+		{conf: LinterConfig{Stream: "stdout"}, exitCode: "0", issues: 1},
+		{conf: LinterConfig{Stream: "stdout"}, exitCode: "2", issues: 1, err: "linter fake failed with exit status 2: build failed"},
+		{conf: LinterConfig{Stream: "stderr"}, exitCode: "2", err: "linter fake failed with exit status 2: a.go:1: found it"},
+		{conf: LinterConfig{Stream: "stderr", ReportExitErrors: true}, exitCode: "0"},
+		{conf: LinterConfig{ReportExitErrors: true, Pattern: "nomatch"}, exitCode: "3", err: "linter fake failed with exit status 3: a.go:1: found it\nbuild failed"},
+	}
+	for _, testcase := range testcases {
+		conf := testcase.conf
+		conf.Command = script
+		if conf.Pattern == "" {
+			conf.Pattern = "PATH:LINE:MESSAGE"
+		}
+		linter, err := NewLinter("fake", conf)
+		require.NoError(t, err)
+		state := &linterState{Linter: linter, issues: make(chan *Issue, 10), vars: Vars{}}
+		err = executeLinter(0, state, []string{script, testcase.exitCode})
+		close(state.issues)
+		if testcase.err == "" {
+			assert.NoError(t, err, "%+v", testcase)
+		} else {
+			assert.EqualError(t, err, testcase.err, "%+v", testcase)
+		}
+		assert.Len(t, state.issues, testcase.issues, "%+v", testcase)
+	}
+}
+
+func TestExecuteLinterDeadline(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not supported on Windows")
+	}
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	script := filepath.Join(tmpdir, "fakelint")
+	survived := filepath.Join(tmpdir, "survived")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho a.go:1: partial\n(sleep 1; touch "+survived+") &\nwait\n"), 0755)
+	require.NoError(t, err)
+
+	linter, err := NewLinter("fake", LinterConfig{Command: script, Pattern: "PATH:LINE:MESSAGE"})
+	require.NoError(t, err)
+	deadline, stop := closeAfter(200 * time.Millisecond)
+	defer stop()
+	state := &linterState{Linter: linter, issues: make(chan *Issue, 10), vars: Vars{}, deadline: deadline, timeout: 200 * time.Millisecond}
+
+	start := time.Now()
+	err = executeLinter(0, state, []string{script})
+	assert.EqualError(t, err, "deadline of 200ms exceeded by linter fake (try increasing --deadline)")
+	assert.True(t, time.Since(start) < time.Second, "linter was not terminated promptly")
+	assert.Len(t, state.issues, 1, "partial results should be reported")
+
+	// The linter's child process should have been terminated with it.
+	time.Sleep(1500 * time.Millisecond)
+	_, err = os.Stat(survived)
+	assert.True(t, os.IsNotExist(err), "child of linter is still running")
+}
+
+func TestExecuteLinterTotalDeadline(t *testing.T) {
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.TotalDeadline = jsonDuration(time.Minute)
+
+	expired, _ := closeAfter(time.Nanosecond)
+	<-expired
+	linter, err := NewLinter("fake", LinterConfig{Command: "fake", Pattern: "PATH:LINE:MESSAGE"})
+	require.NoError(t, err)
+	state := &linterState{Linter: linter, vars: Vars{}, totalDeadline: expired}
+
+	err = executeLinter(0, state, []string{"fake"})
+	assert.EqualError(t, err, "linter fake did not complete within the total deadline of 1m0s (try increasing --total-deadline)")
+
+	executions := &executionLog{}
+	executions.add("fake", nil, err)
+	executions.add("fake", nil, err)
+	executions.add("other", nil, nil)
+	assert.Equal(t, []*linterTimeout{{Linter: "fake", Timeout: time.Minute, Total: true}}, executions.timeouts())
+}
+
+func TestExecuteLinterInterrupted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not supported on Windows")
+	}
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	defer func(original *linterProcesses) { runningLinters = original }(runningLinters)
+	runningLinters = &linterProcesses{interrupted: make(chan struct{})}
+
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho a.go:1: partial\nsleep 30 &\nwait\n"), 0755)
+	require.NoError(t, err)
+
+	linter, err := NewLinter("fake", LinterConfig{Command: script, Pattern: "PATH:LINE:MESSAGE"})
+	require.NoError(t, err)
+	state := &linterState{Linter: linter, issues: make(chan *Issue, 10), vars: Vars{}}
+	errch := make(chan error, 1)
+	go func() { errch <- executeLinter(0, state, []string{script}) }()
+
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	runningLinters.interrupt()
+	assert.True(t, time.Since(start) < time.Second, "linter was not terminated promptly")
+	assert.EqualError(t, <-errch, "linter fake interrupted")
+	assert.Len(t, state.issues, 1, "partial results should be reported")
+
+	// Linters can not be started once interrupted.
+	assert.EqualError(t, executeLinter(1, state, []string{script}), "linter fake interrupted")
+}
+
+// runFakeLinterOverDirectories runs a linter defined by script and
+// linterConfig over count directories, failing the test if the run does not
+// complete.
+func runFakeLinterOverDirectories(t *testing.T, tmpdir, script string, linterConfig LinterConfig, count int) ([]*Issue, []error) {
+	paths := []string{}
+	for i := 0; i < count; i++ {
+		dir := fmt.Sprintf("pkg%d", i)
+		mkDir(t, tmpdir, dir)
+		paths = append(paths, "./"+dir)
+	}
+	err := ioutil.WriteFile(filepath.Join(tmpdir, "fakelint"), []byte(script), 0755)
+	require.NoError(t, err)
+	linterConfig.Command = filepath.Join(tmpdir, "fakelint")
+	linterConfig.PartitionStrategy = partitionPathsByDirectory
+	config.Linters["fake"] = StringOrLinterConfig(linterConfig)
+	config.Enable = []string{"fake"}
+
+	done := make(chan struct{})
+	issues, errs := []*Issue{}, []error{}
+	go func() {
+		defer close(done)
+		issueCh, errch, _ := runLinters(lintersFromConfig(config), paths, 2, nil, nil, nil)
+		for issue := range issueCh {
+			issues = append(issues, issue)
+		}
+		for err := range errch {
+			errs = append(errs, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("linters did not complete")
+	}
+	return issues, errs
+}
+
+func TestRunLintersTotalDeadlineOverManyPartitions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	config.TotalDeadline = jsonDuration(time.Millisecond)
+
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	_, errs := runFakeLinterOverDirectories(t, tmpdir, "#!/bin/sh\nsleep 1\n", LinterConfig{Pattern: "PATH:LINE:MESSAGE"}, 10)
+	assert.Len(t, errs, 10)
+	for _, err := range errs {
+		_, ok := err.(*linterTimeout)
+		assert.True(t, ok, "%s", err)
+	}
+}
+
+func TestRunLintersReportsFailureOfEveryPartition(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	script := "#!/bin/sh\necho \"$1/file.go:1: found\"\necho broken >&2\nexit 1\n"
+	issues, errs := runFakeLinterOverDirectories(t, tmpdir, script, LinterConfig{Pattern: "PATH:LINE:MESSAGE", Stream: "stdout"}, 10)
+	assert.Len(t, issues, 10)
+	assert.Len(t, errs, 10)
+	for _, err := range errs {
+		assert.EqualError(t, err, "linter fake failed with exit status 1: broken")
+	}
+}
+
+func TestRunLintersInterruptedWithQueuedPartitions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not supported on Windows")
+	}
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	defer func(original *linterProcesses) { runningLinters = original }(runningLinters)
+	runningLinters = &linterProcesses{interrupted: make(chan struct{})}
+
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	timer := time.AfterFunc(300*time.Millisecond, runningLinters.interrupt)
+	defer timer.Stop()
+	script := "#!/bin/sh\necho \"$1/file.go:1: partial\"\nsleep 30 &\nwait\n"
+	issues, errs := runFakeLinterOverDirectories(t, tmpdir, script, LinterConfig{Pattern: "PATH:LINE:MESSAGE"}, 10)
+	assert.Len(t, issues, 2, "partial results of the running linters should be reported")
+	assert.Len(t, errs, 10)
+	for _, err := range errs {
+		assert.EqualError(t, err, "linter fake interrupted")
+	}
+}
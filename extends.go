@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Lists which a configuration file replaces, rather than appends to.
+var replacedConfigLists = map[string]bool{
+	"Sort": true,
+}
+
+// configLoader loads a configuration file, and the files it extends, into a
+// Config. The files named by Extends are applied first, in order, followed by
+// the file itself. Each file is applied at most once. Settings from each file
+// are applied as follows:
+//
+//   - maps, such as Linters and Severity, are merged key by key
+//   - lists, such as Exclude, are appended to, except those in replacedConfigLists
+//   - Enable adds linters and Disable removes them, except that the first
+//     Enable replaces the default set of linters
+//   - all other settings are replaced
+type configLoader struct {
+	config *Config
+	// Absolute paths of the files being loaded, to detect cycles.
+	loading []string
+	loaded  map[string]bool
+	enabled bool
+	// Files that contributed to each setting, keyed by field name.
+	sources map[string][]string
+}
+
+func newConfigLoader(config *Config) *configLoader {
+	sources := map[string][]string{}
+	for name, files := range config.configSources {
+		sources[name] = files
+	}
+	return &configLoader{config: config, loaded: map[string]bool{}, sources: sources}
+}
+
+func (l *configLoader) load(path string) error {
+	if err := l.loadFile(path); err != nil {
+		return err
+	}
+	l.config.configFile = path
+	l.config.configSources = l.sources
+	return nil
+}
+
+func (l *configLoader) loadFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for i, loading := range l.loading {
+		if loading == abs {
+			chain := append(append([]string{}, l.loading[i:]...), abs)
+			return fmt.Errorf("configuration files extend each other in a cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+	if l.loaded[abs] {
+		return nil
+	}
+	l.loading = append(l.loading, abs)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data, err := convertConfigFile(path, raw)
+	if err != nil {
+		return err
+	}
+	if err := checkConfigKeys(path, raw, data); err != nil {
+		return err
+	}
+	file := &Config{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return newConfigFileError(path, raw, data, err)
+	}
+	for _, extends := range file.Extends {
+		if !filepath.IsAbs(extends) {
+			extends = filepath.Join(filepath.Dir(path), extends)
+		}
+		if err := l.loadFile(extends); err != nil {
+			return err
+		}
+	}
+	if err := l.apply(data, path); err != nil {
+		return err
+	}
+	l.loaded[abs] = true
+	return nil
+}
+
+// apply the settings in a JSON encoded Config, ignoring Extends. source
+// describes where the settings came from.
+func (l *configLoader) apply(data []byte, source string) error {
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("%s: %s", source, err)
+	}
+	file := &Config{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return fmt.Errorf("%s: %s", source, err)
+	}
+
+	dst := reflect.ValueOf(l.config).Elem()
+	src := reflect.ValueOf(file).Elem()
+	for _, name := range configFieldNames(keys) {
+		replace := false
+		switch name {
+		case "Extends":
+			continue
+
+		case "Enable":
+			if !l.enabled {
+				l.config.Enable = nil
+				l.enabled = true
+				replace = true
+			}
+			l.config.Enable = append(l.config.Enable, file.Enable...)
+
+		case "Disable":
+			l.config.Disable = append(l.config.Disable, file.Disable...)
+			l.config.Enable = removeStrings(l.config.Enable, file.Disable)
+			l.addSource("Enable", source, false)
+
+		default:
+			replace = mergeConfigField(dst.FieldByName(name), src.FieldByName(name), replacedConfigLists[name])
+		}
+		l.addSource(name, source, replace)
+	}
+	return nil
+}
+
+func (l *configLoader) addSource(name, source string, replace bool) {
+	if replace {
+		l.sources[name] = []string{source}
+	} else {
+		l.sources[name] = append(append([]string{}, l.sources[name]...), source)
+	}
+}
+
+// configFieldNames returns the names of the Config fields set by the given
+// JSON keys, in the order they are declared.
+func configFieldNames(keys map[string]json.RawMessage) []string {
+	out := []string{}
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		for key := range keys {
+			if strings.EqualFold(key, field.Name) {
+				out = append(out, field.Name)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// mergeConfigField merges src into dst, returning true if dst was replaced.
+func mergeConfigField(dst, src reflect.Value, replaceList bool) bool {
+	switch dst.Kind() {
+	case reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for _, key := range src.MapKeys() {
+			dst.SetMapIndex(key, src.MapIndex(key))
+		}
+		return false
+
+	case reflect.Slice:
+		if !replaceList {
+			dst.Set(reflect.AppendSlice(dst, src))
+			return false
+		}
+	}
+	dst.Set(src)
+	return true
+}
+
+func removeStrings(list []string, remove []string) []string {
+	out := []string{}
+	for _, s := range list {
+		removed := false
+		for _, r := range remove {
+			if s == r {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// debugConfigSources logs each setting loaded from configuration files, with
+// the files that contributed to it.
+func debugConfigSources(config *Config) {
+	value := reflect.ValueOf(config).Elem()
+	names := make([]string, 0, len(config.configSources))
+	for name := range config.configSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		debug("config: %s = %s (from %s)", name, formatConfigValue(value.FieldByName(name)),
+			strings.Join(config.configSources[name], " -> "))
+	}
+}
+
+func formatConfigValue(value reflect.Value) string {
+	if encoded, err := json.Marshal(value.Interface()); err == nil {
+		return string(encoded)
+	}
+	if value.Kind() == reflect.Map {
+		keys := []string{}
+		for _, key := range value.MapKeys() {
+			keys = append(keys, fmt.Sprintf("%v", key.Interface()))
+		}
+		sort.Strings(keys)
+		return "{" + strings.Join(keys, ", ") + "}"
+	}
+	return fmt.Sprintf("%v", value.Interface())
+}
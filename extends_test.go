@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestConfigLoaderExtends(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "shared")
+	writeConfigFile(t, filepath.Join(tmpdir, "shared", "base.json"), `{
+		"Enable": ["golint", "vet", "errcheck"],
+		"Disable": ["errcheck"],
+		"Severity": {"golint": "error", "vet": "error"},
+		"Exclude": ["base"],
+		"Sort": ["path"],
+		"Deadline": "1m"
+	}`)
+	writeConfigFile(t, filepath.Join(tmpdir, "shared", "style.yaml"), `
+Enable: [lll]
+Severity: {lll: warning}
+`)
+	writeConfigFile(t, filepath.Join(tmpdir, "repo.json"), `{
+		"Extends": ["shared/base.json", "shared/style.yaml"],
+		"Enable": ["errcheck"],
+		"Disable": ["vet"],
+		"Severity": {"golint": "warning"},
+		"Exclude": ["repo"],
+		"Sort": ["linter"]
+	}`)
+
+	actual := &Config{Enable: []string{"default"}, Severity: map[string]string{"test": "error"}}
+	require.NoError(t, newConfigLoader(actual).load(filepath.Join(tmpdir, "repo.json")))
+
+	assert.Equal(t, []string{"golint", "lll", "errcheck"}, actual.Enable)
+	assert.Equal(t, map[string]string{"test": "error", "golint": "warning", "vet": "error", "lll": "warning"}, actual.Severity)
+	assert.Equal(t, []string{"base", "repo"}, actual.Exclude)
+	assert.Equal(t, []string{"linter"}, actual.Sort)
+	assert.Equal(t, time.Minute, actual.Deadline.Duration())
+
+	assert.Equal(t, []string{filepath.Join(tmpdir, "repo.json")}, actual.configSources["Sort"])
+	assert.Equal(t, []string{filepath.Join(tmpdir, "shared", "base.json")}, actual.configSources["Deadline"])
+	assert.Equal(t, []string{
+		filepath.Join(tmpdir, "shared", "base.json"),
+		filepath.Join(tmpdir, "repo.json"),
+	}, actual.configSources["Exclude"])
+}
+
+func TestConfigLoaderExtendsOnce(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	writeConfigFile(t, filepath.Join(tmpdir, "common.json"), `{"Exclude": ["common"]}`)
+	writeConfigFile(t, filepath.Join(tmpdir, "a.json"), `{"Extends": ["common.json"]}`)
+	writeConfigFile(t, filepath.Join(tmpdir, "b.json"), `{"Extends": ["common.json"]}`)
+	writeConfigFile(t, filepath.Join(tmpdir, "root.json"), `{"Extends": ["a.json", "b.json"]}`)
+
+	actual := &Config{}
+	require.NoError(t, newConfigLoader(actual).load(filepath.Join(tmpdir, "root.json")))
+	assert.Equal(t, []string{"common"}, actual.Exclude)
+}
+
+func TestConfigLoaderExtendsCycle(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	writeConfigFile(t, filepath.Join(tmpdir, "a.json"), `{"Extends": ["b.json"]}`)
+	writeConfigFile(t, filepath.Join(tmpdir, "b.json"), `{"Extends": ["a.json"]}`)
+
+	err := newConfigLoader(&Config{}).load(filepath.Join(tmpdir, "a.json"))
+	require.Error(t, err)
+	a, b := filepath.Join(tmpdir, "a.json"), filepath.Join(tmpdir, "b.json")
+	assert.Equal(t, "configuration files extend each other in a cycle: "+a+" -> "+b+" -> "+a, err.Error())
+}
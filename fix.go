@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// fixIssues lints paths, runs the Fix command of each linter that reported
+// issues over the files it reported them in, and then lints paths again,
+// outputting the issues that remain. With --dry-run the fixes are instead
+// applied to copies of the files and written to w as a unified diff.
+func fixIssues(w io.Writer, linters map[string]*Linter, paths []string, exclude, include *regexp.Regexp) int {
+	issues, errch, _ := runLinters(linters, paths, config.Concurrency, exclude, include, nil)
+	files := issueFilesByLinter(linters, issues)
+	for err := range errch {
+		warning("%s", err)
+	}
+
+	if config.DryRun {
+		status, err := dryRunFixes(w, linters, files)
+		if err != nil {
+			warning("%s", err)
+			return 2
+		}
+		return status
+	}
+	fixed := applyFixes(linters, files, "")
+	debug("fixed issues reported by %d linters, linting again", fixed)
+	issues, errch, executions := runLinters(linters, paths, config.Concurrency, exclude, include, nil)
+	return outputIssues(linters, executions, issues, errch)
+}
+
+// issueFilesByLinter returns the files each linter with a Fix command reported
+// issues in.
+func issueFilesByLinter(linters map[string]*Linter, issues chan *Issue) map[string]*stringSet {
+	out := map[string]*stringSet{}
+	for issue := range issues {
+		if issue.Path == "" {
+			continue
+		}
+		// Aggregated issues list every linter that reported them.
+		for _, name := range strings.Split(issue.Linter, ", ") {
+			linter, ok := linters[name]
+			if !ok || linter.Fix == "" {
+				continue
+			}
+			if out[name] == nil {
+				out[name] = newStringSet()
+			}
+			out[name].add(issue.Path)
+		}
+	}
+	return out
+}
+
+// fixesFiles returns true if the Fix command of linter is passed the files to
+// fix, rather than their packages, as the linter is run on files.
+func fixesFiles(linter *Linter) bool {
+	switch linter.PartitionStrategy.String() {
+	case "files", "files-by-package":
+		return true
+	}
+	return false
+}
+
+// fixArgs returns the paths to pass to the Fix command of linter to fix
+// files.
+func fixArgs(linter *Linter, files []string) []string {
+	if fixesFiles(linter) {
+		return files
+	}
+	dirs := newStringSet()
+	for _, file := range files {
+		dirs.add(relativePackagePath(filepath.Dir(file)))
+	}
+	out := dirs.asSlice()
+	sort.Strings(out)
+	return out
+}
+
+// applyFixes runs the Fix command of each linter over the files it reported
+// issues in, from dir if it is not empty, returning the number of linters
+// whose fixes were applied. Fixes are applied one linter at a time, as they
+// may modify the same files.
+func applyFixes(linters map[string]*Linter, files map[string]*stringSet, dir string) int {
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	vars := linterVars(config)
+	fixed := 0
+	for _, name := range names {
+		linter := linters[name]
+		paths := files[name].asSlice()
+		sort.Strings(paths)
+		if err := runFix(linter, vars, fixArgs(linter, paths), dir); err != nil {
+			warning("%s", err)
+			continue
+		}
+		fixed++
+	}
+	return fixed
+}
+
+func runFix(linter *Linter, vars Vars, paths []string, dir string) error {
+	cmdArgs, err := parseCommand(vars.Replace(linter.Fix))
+	if err != nil {
+		return fmt.Errorf("invalid fix command for %s: %s", linter.Name, err)
+	}
+	var partitions [][]string
+	// Packages are passed as directories when fixing copies of them, as their
+	// import paths would refer to the originals.
+	if fixesFiles(linter) || dir != "" {
+		partitions, err = partitionPathsAsDirectories(cmdArgs, paths)
+	} else {
+		partitions, err = linter.PartitionStrategy(cmdArgs, paths)
+	}
+	if err != nil {
+		return err
+	}
+	for _, args := range partitions {
+		debug("fixing %s issues with %s", linter.Name, strings.Join(args, " "))
+		cmd := exec.Command(args[0], args[1:]...) // nolint: gas
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to fix %s issues: %s: %s", linter.Name, err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// readFixableFiles returns the contents of every Go file that the fixes may
+// modify.
+func readFixableFiles(linters map[string]*Linter, files map[string]*stringSet) map[string][]byte {
+	out := map[string][]byte{}
+	for name, set := range files {
+		paths := set.asSlice()
+		if !fixesFiles(linters[name]) {
+			// The fix may modify any file in the packages.
+			var err error
+			if paths, err = pathsToFileGlobs(fixArgs(linters[name], paths)); err != nil {
+				warning("%s", err)
+			}
+		}
+		for _, path := range paths {
+			if _, ok := out[path]; ok {
+				continue
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				warning("%s", err)
+				continue
+			}
+			out[path] = data
+		}
+	}
+	return out
+}
+
+// dryRunFixes applies the fixes to copies of the files they may modify, in a
+// temporary directory mirroring their absolute paths, and writes the changes
+// to w as a unified diff. It returns the exit status: 1 if there were any
+// changes.
+func dryRunFixes(w io.Writer, linters map[string]*Linter, files map[string]*stringSet) (int, error) {
+	originals := readFixableFiles(linters, files)
+	root, err := ioutil.TempDir("", "gometalinter-fix")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(root) // nolint: errcheck
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return 0, err
+	}
+	copies := map[string]string{}
+	for path, data := range originals {
+		copies[path] = sandboxPath(root, cwd, path)
+		if err := writeFileCopy(path, copies[path], data); err != nil {
+			return 0, err
+		}
+	}
+	// Fixes that load packages need the module they are in.
+	if mod, err := findModule(cwd); err == nil && mod != nil {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			path := filepath.Join(mod.Dir, name)
+			if data, err := ioutil.ReadFile(path); err == nil {
+				if err := writeFileCopy(path, sandboxPath(root, cwd, path), data); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+
+	sandboxed := map[string]*stringSet{}
+	for name, set := range files {
+		sandboxed[name] = newStringSet()
+		for _, path := range set.asSlice() {
+			if filepath.IsAbs(path) {
+				path = sandboxPath(root, cwd, path)
+			}
+			sandboxed[name].add(path)
+		}
+	}
+	dir := sandboxPath(root, cwd, cwd)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	applyFixes(linters, sandboxed, dir)
+	return writeFixDiff(w, originals, copies), nil
+}
+
+// sandboxPath returns the path within root that mirrors path, which is
+// relative to cwd if it is not absolute.
+func sandboxPath(root, cwd, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	return filepath.Join(root, strings.TrimPrefix(path, filepath.VolumeName(path)))
+}
+
+func writeFileCopy(original, path string, data []byte) error {
+	info, err := os.Stat(original)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, info.Mode())
+}
+
+// writeFixDiff writes the changes made to the copies of files to w as a
+// unified diff, and returns the exit status: 1 if there were any changes.
+func writeFixDiff(w io.Writer, originals map[string][]byte, copies map[string]string) int {
+	paths := []string{}
+	for path := range originals {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	status := 0
+	for _, path := range paths {
+		fixed, err := ioutil.ReadFile(copies[path])
+		if err != nil {
+			warning("%s", err)
+			continue
+		}
+		diff := unifiedDiff(filepath.ToSlash(path), originals[path], fixed)
+		if diff == "" {
+			continue
+		}
+		status = 1
+		fmt.Fprint(w, diff)
+	}
+	return status
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFixableLinter(t *testing.T, tmpdir string) map[string]*Linter {
+	lint := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(lint, []byte("#!/bin/sh\ngrep -n -H bad \"$@\"\n"), 0755)
+	require.NoError(t, err)
+	fix := filepath.Join(tmpdir, "fakefix")
+	err = ioutil.WriteFile(fix, []byte("#!/bin/sh\nfor f in \"$@\"; do sed -e 's/bad fixable/good/' \"$f\" > \"$f.tmp\" && mv \"$f.tmp\" \"$f\"; done\n"), 0755)
+	require.NoError(t, err)
+
+	linter, err := NewLinter("fake", LinterConfig{
+		Command:           lint,
+		Pattern:           "PATH:LINE:MESSAGE",
+		Fix:               fix,
+		PartitionStrategy: partitionPathsAsFiles,
+	})
+	require.NoError(t, err)
+	return map[string]*Linter{"fake": linter}
+}
+
+func TestFixIssuesDryRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.Fix = true
+	config.DryRun = true
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	linters := setupFixableLinter(t, tmpdir)
+
+	mkDir(t, tmpdir, "pkg")
+	source := "package foo\n\n// bad fixable\n"
+	writeFile(t, filepath.Join(tmpdir, "pkg", "file.go"), source)
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(tmpdir, "pkg", "file.go"), modTime, modTime))
+
+	out := &bytes.Buffer{}
+	status := fixIssues(out, linters, []string{"./pkg"}, nil, nil)
+	assert.Equal(t, 1, status)
+	expected := "--- a/pkg/file.go\n+++ b/pkg/file.go\n@@ -1,3 +1,3 @@\n package foo\n \n-// bad fixable\n+// good\n"
+	assert.Equal(t, expected, out.String())
+
+	path := filepath.Join(tmpdir, "pkg", "file.go")
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, source, string(data), "file should not be modified")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, modTime.Equal(info.ModTime()), "file should not be written")
+}
+
+func TestIssueFilesByLinter(t *testing.T) {
+	linters := map[string]*Linter{
+		"gofmt":  {Name: "gofmt", LinterConfig: LinterConfig{Fix: "gofmt -w"}},
+		"golint": {Name: "golint"},
+	}
+	issues := make(chan *Issue, 3)
+	issues <- &Issue{Linter: "gofmt", Path: "a.go"}
+	issues <- &Issue{Linter: "gofmt, golint", Path: "b.go"}
+	issues <- &Issue{Linter: "golint", Path: "c.go"}
+	close(issues)
+
+	files := issueFilesByLinter(linters, issues)
+	require.Len(t, files, 1)
+	assert.Equal(t, 2, files["gofmt"].size())
+}
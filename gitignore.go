@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// gitIgnoreRule is a pattern from a .gitignore or .git/info/exclude file.
+type gitIgnoreRule struct {
+	// Absolute directory the pattern is relative to.
+	base string
+	// Elements of the pattern, split on "/".
+	pattern []string
+	// True if the pattern is matched against the path relative to base,
+	// rather than against the base name.
+	anchored bool
+	dirOnly  bool
+	negate   bool
+}
+
+// gitIgnore matches paths against the ignore rules of the git repositories
+// containing them. Rules are loaded as directories are first matched.
+type gitIgnore struct {
+	lock  sync.Mutex
+	rules []*gitIgnoreRule
+	// Ignore files and directories whose rules have been loaded.
+	loaded map[string]bool
+	// Repository root of each directory looked up, or "" if not in one.
+	roots map[string]string
+}
+
+func newGitIgnore() *gitIgnore {
+	return &gitIgnore{loaded: map[string]bool{}, roots: map[string]string{}}
+}
+
+// repositoryRoot returns the root of the git repository containing dir, or ""
+// if it is not in one.
+func (g *gitIgnore) repositoryRoot(dir string) string {
+	if root, ok := g.roots[dir]; ok {
+		return root
+	}
+	root := ""
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = g.repositoryRoot(parent)
+	}
+	g.roots[dir] = root
+	return root
+}
+
+// load loads the rules that apply within dir, which must be absolute, from
+// the repository root down, returning the repository root.
+func (g *gitIgnore) load(dir string) string {
+	root := g.repositoryRoot(dir)
+	if root == "" {
+		return ""
+	}
+	if exclude := filepath.Join(root, ".git", "info", "exclude"); !g.loaded[exclude] {
+		g.loaded[exclude] = true
+		g.loadFile(exclude, root)
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return root
+	}
+	current := root
+	for _, element := range append([]string{"."}, strings.Split(rel, string(filepath.Separator))...) {
+		current = filepath.Join(current, element)
+		if !g.loaded[current] {
+			g.loaded[current] = true
+			g.loadFile(filepath.Join(current, ".gitignore"), current)
+		}
+	}
+	return root
+}
+
+func (g *gitIgnore) loadFile(path, base string) {
+	r, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer r.Close() // nolint: errcheck
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if rule := parseGitIgnoreRule(base, scanner.Text()); rule != nil {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		warning("failed to read %s: %s", path, err)
+	}
+}
+
+// parseGitIgnoreRule parses a line of a .gitignore file in base, returning nil
+// for blank lines and comments.
+func parseGitIgnoreRule(base, line string) *gitIgnoreRule {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	rule := &gitIgnoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// A pattern containing a slash other than at the end is relative to the
+	// directory of the .gitignore file.
+	rule.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return nil
+	}
+	rule.pattern = strings.Split(line, "/")
+	return rule
+}
+
+func (r *gitIgnoreRule) matches(path string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(r.base, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if !r.anchored {
+		return matchGlobElements(r.pattern, []string{filepath.Base(path)})
+	}
+	return matchGlobElements(r.pattern, strings.Split(filepath.ToSlash(rel), "/"))
+}
+
+// ignored returns true if the last rule matching path excludes it. Parent
+// directories are not checked.
+func (g *gitIgnore) ignored(path string, isDir bool) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.load(filepath.Dir(path)) == "" {
+		return false
+	}
+	ignored := false
+	for _, rule := range g.rules {
+		if rule.matches(path, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// fileIgnored returns true if the file at path, or any directory containing
+// it within its repository, is ignored.
+func (g *gitIgnore) fileIgnored(path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	g.lock.Lock()
+	root := g.load(filepath.Dir(path))
+	g.lock.Unlock()
+	if root == "" {
+		return false
+	}
+	if g.ignored(path, false) {
+		return true
+	}
+	for dir := filepath.Dir(path); dir != root && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if g.ignored(dir, true) {
+			return true
+		}
+	}
+	return false
+}
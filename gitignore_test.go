@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePathsWithGitIgnore(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.GitIgnore = true
+
+	mkDir(t, tmpdir, ".git", "info")
+	writeFile(t, filepath.Join(tmpdir, ".git", "info", "exclude"), "/local\n")
+	writeFile(t, filepath.Join(tmpdir, ".gitignore"), "# generated\nbuild/\n/gen/*\n!/gen/keep\n")
+	mkDir(t, tmpdir, "pkg")
+	mkDir(t, tmpdir, "pkg", "build")
+	mkDir(t, tmpdir, "gen", "api")
+	mkDir(t, tmpdir, "gen", "keep")
+	mkDir(t, tmpdir, "local")
+	mkDir(t, tmpdir, "sub", "ignored")
+	writeFile(t, filepath.Join(tmpdir, "sub", ".gitignore"), "ignored\n")
+
+	paths := resolvePaths([]string{"./..."}, nil)
+	expected := []string{"./gen/keep", "./pkg"}
+	assert.Equal(t, expected, paths)
+
+	ignore := newGitIgnore()
+	assert.True(t, ignore.fileIgnored(filepath.Join("pkg", "build", "file.go")))
+	assert.True(t, ignore.fileIgnored(filepath.Join("sub", "ignored", "file.go")))
+	assert.False(t, ignore.fileIgnored(filepath.Join("pkg", "file.go")))
+}
+
+func TestParseGitIgnoreRule(t *testing.T) {
+	assert.Nil(t, parseGitIgnoreRule("/repo", "# comment"))
+	assert.Nil(t, parseGitIgnoreRule("/repo", "   "))
+	assert.Equal(t, &gitIgnoreRule{base: "/repo", pattern: []string{"*.pb.go"}}, parseGitIgnoreRule("/repo", "*.pb.go"))
+	assert.Equal(t, &gitIgnoreRule{base: "/repo", pattern: []string{"a", "**", "b"}, anchored: true, dirOnly: true, negate: true},
+		parseGitIgnoreRule("/repo", "!/a/**/b/"))
+	assert.Equal(t, &gitIgnoreRule{base: "/repo", pattern: []string{"#hash"}}, parseGitIgnoreRule("/repo", `\#hash`))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+// gitlabIssue is a single entry in a GitLab Code Quality report.
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// gitlabSeverity maps a gometalinter severity to a GitLab Code Quality severity.
+func gitlabSeverity(severity Severity) string {
+	switch severity {
+	case Error:
+		return "major"
+	case Warning:
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+// gitlabFingerprinter returns stable identifiers for issues, so that GitLab
+// can track them across pipelines. Issues are fingerprinted as for baselines,
+// by the source surrounding them rather than their line, so that moving code
+// above an issue does not change it. Repeated fingerprints are numbered to
+// keep them unique.
+type gitlabFingerprinter struct {
+	baseline *baselineFingerprinter
+	seen     map[string]int
+}
+
+func newGitLabFingerprinter() *gitlabFingerprinter {
+	return &gitlabFingerprinter{baseline: newBaselineFingerprinter(), seen: map[string]int{}}
+}
+
+func (f *gitlabFingerprinter) Fingerprint(issue *Issue) string {
+	fingerprint := f.baseline.Fingerprint(issue)
+	f.seen[fingerprint]++
+	if n := f.seen[fingerprint]; n > 1 {
+		h := md5.New() // nolint: gas
+		fmt.Fprintf(h, "%s\x00%d", fingerprint, n)
+		return fmt.Sprintf("%x", h.Sum(nil))
+	}
+	return fingerprint
+}
+
+func newGitLabIssue(fingerprinter *gitlabFingerprinter, issue *Issue) *gitlabIssue {
+	return &gitlabIssue{
+		Description: issue.Message,
+		CheckName:   issue.Linter,
+		Fingerprint: fingerprinter.Fingerprint(issue),
+		Severity:    gitlabSeverity(issue.Severity),
+		Location: gitlabLocation{
+			Path:  filepath.ToSlash(issue.Path),
+			Lines: gitlabLines{Begin: issue.Line},
+		},
+	}
+}
+
+func outputToGitLab(issues chan *Issue) int {
+	fmt.Println("[")
+	status := 0
+	fingerprinter := newGitLabFingerprinter()
+	for issue := range issues {
+		if config.Errors && issue.Severity != Error {
+			continue
+		}
+		if status != 0 {
+			fmt.Printf(",\n")
+		}
+		d, err := json.Marshal(newGitLabIssue(fingerprinter, issue))
+		kingpin.FatalIfError(err, "")
+		fmt.Printf("  %s", d)
+		status = 1
+	}
+	fmt.Printf("\n]\n")
+	return status
+}
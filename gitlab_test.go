@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGitLabIssue(t *testing.T) {
+	issue := &Issue{Linter: "vet", Severity: Error, Path: "foo/a.go", Line: 3, Col: 2, Message: "bad"}
+	actual := newGitLabIssue(newGitLabFingerprinter(), issue)
+	assert.Equal(t, "bad", actual.Description)
+	assert.Equal(t, "vet", actual.CheckName)
+	assert.Equal(t, "major", actual.Severity)
+	assert.Equal(t, gitlabLocation{Path: "foo/a.go", Lines: gitlabLines{Begin: 3}}, actual.Location)
+	assert.Len(t, actual.Fingerprint, 32)
+
+	same := *issue
+	assert.Equal(t, actual.Fingerprint, newGitLabIssue(newGitLabFingerprinter(), &same).Fingerprint)
+	other := *issue
+	other.Message = "worse"
+	assert.NotEqual(t, actual.Fingerprint, newGitLabIssue(newGitLabFingerprinter(), &other).Fingerprint)
+}
+
+func TestGitLabFingerprintIgnoresLine(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	path := filepath.Join(tmpdir, "a.go")
+
+	writeFile(t, path, "package foo\n\nfunc bad() {}\n")
+	issue := &Issue{Linter: "vet", Path: path, Line: 3, Col: 1, Message: "bad"}
+	before := newGitLabFingerprinter().Fingerprint(issue)
+
+	writeFile(t, path, "package foo\n\nfunc good() {}\n\nfunc bad() {}\n")
+	moved := *issue
+	moved.Line = 5
+	assert.Equal(t, before, newGitLabFingerprinter().Fingerprint(&moved))
+
+	// Identical issues are still given distinct fingerprints.
+	fingerprinter := newGitLabFingerprinter()
+	assert.NotEqual(t, fingerprinter.Fingerprint(issue), fingerprinter.Fingerprint(issue))
+}
@@ -12,6 +12,63 @@ import (
 // DefaultIssueFormat used to print an issue
 const DefaultIssueFormat = "{{.Path}}:{{.Line}}:{{if .Col}}{{.Col}}{{end}}:{{.Severity}}: {{.Message}} ({{.Linter}})"
 
+// IssueFormatPresets are named formats which can be passed to --format in
+// place of a template.
+var IssueFormatPresets = map[string]string{
+	"github-actions": "::{{actionsLevel .Severity}} file={{actionsProperty .Path}},line={{.Line}}{{if .Col}},col={{.Col}}{{end}},title={{actionsProperty .Linter}}::{{actionsData .Message}}",
+}
+
+func issueFormatPresetNames() []string {
+	names := make([]string, 0, len(IssueFormatPresets))
+	for name := range IssueFormatPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// issueFormatFuncs are the functions available to issue format templates.
+var issueFormatFuncs = template.FuncMap{
+	"actionsLevel":    actionsLevel,
+	"actionsData":     actionsData,
+	"actionsProperty": actionsProperty,
+}
+
+// actionsLevel maps a severity to a GitHub Actions workflow command.
+func actionsLevel(severity Severity) string {
+	switch severity {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+var actionsDataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// actionsData escapes the message of a GitHub Actions workflow command.
+func actionsData(s string) string {
+	return actionsDataEscaper.Replace(s)
+}
+
+var actionsPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// actionsProperty escapes a property value of a GitHub Actions workflow command.
+func actionsProperty(s string) string {
+	return actionsPropertyEscaper.Replace(s)
+}
+
+// newIssueFormatTemplate parses an issue format template, which may also be
+// the name of one of IssueFormatPresets.
+func newIssueFormatTemplate(format string) (*template.Template, error) {
+	if preset, ok := IssueFormatPresets[format]; ok {
+		format = preset
+	}
+	return template.New("output").Funcs(issueFormatFuncs).Parse(format)
+}
+
 // Severity of linter message
 type Severity string
 
@@ -22,13 +79,19 @@ const (
 )
 
 type Issue struct {
-	Linter     string   `json:"linter"`
-	Severity   Severity `json:"severity"`
-	Path       string   `json:"path"`
-	Line       int      `json:"line"`
-	Col        int      `json:"col"`
-	Message    string   `json:"message"`
-	formatTmpl *template.Template
+	Linter   string   `json:"linter"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Line     int      `json:"line"`
+	Col      int      `json:"col"`
+	Message  string   `json:"message"`
+	// Identifier of the check that reported the issue, if the linter reports
+	// one, such as "G101" for gas.
+	Rule string `json:"rule,omitempty"`
+	// Build contexts the issue was reported under, when linting with a build
+	// matrix.
+	BuildContexts []string `json:"build_contexts,omitempty"`
+	formatTmpl    *template.Template
 }
 
 // NewIssue returns a new issue. Returns an error if formatTmpl is not a valid
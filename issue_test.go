@@ -37,3 +37,21 @@ func TestCompareOrderWithMessage(t *testing.T) {
 	assert.True(t, CompareIssue(issueM, issueU, order))
 	assert.False(t, CompareIssue(issueU, issueM, order))
 }
+
+func TestGitHubActionsFormatPreset(t *testing.T) {
+	tmpl, err := newIssueFormatTemplate("github-actions")
+	require.NoError(t, err)
+
+	issue, err := NewIssue("vet", tmpl)
+	require.NoError(t, err)
+	issue.Path = "foo,bar.go"
+	issue.Line = 3
+	issue.Col = 4
+	issue.Severity = Error
+	issue.Message = "100% bad\nreally"
+	assert.Equal(t, "::error file=foo%2Cbar.go,line=3,col=4,title=vet::100%25 bad%0Areally", issue.String())
+
+	issue.Col = 0
+	issue.Severity = "info"
+	assert.Equal(t, "::notice file=foo%2Cbar.go,line=3,title=vet::100%25 bad%0Areally", issue.String())
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Issue fields that can be read from the output of linters whose Format is
+// "json".
+var jsonIssueFields = map[string]bool{
+	"path":     true,
+	"line":     true,
+	"col":      true,
+	"message":  true,
+	"severity": true,
+	"rule":     true,
+}
+
+// processJSONOutput parses issues from the output of a linter whose Format
+// is "json". The output may be a single JSON document or a sequence of them,
+// such as JSON lines. Lines that are not JSON, such as warnings written to
+// stderr, are skipped. Returns the number of issues found.
+func processJSONOutput(dbg debugFunction, state *linterState, out []byte) (int, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		warning("failed to get working directory %s", err)
+	}
+
+	hits := 0
+	rest := out
+	for len(bytes.TrimSpace(rest)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(rest))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			dbg("%s: skipping output that is not JSON: %s", state.Name, err)
+			next := bytes.IndexByte(rest, '\n')
+			if next < 0 {
+				break
+			}
+			rest = rest[next+1:]
+			continue
+		}
+		rest = rest[dec.InputOffset():]
+
+		if state.Issues != "" {
+			value = lookupJSONPath(value, state.Issues)
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, value := range values {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			hits++
+			issue, vars, err := newIssueFromJSON(state, cwd, object)
+			if err != nil {
+				return hits, err
+			}
+			if issue != nil {
+				emitIssue(state, issue, vars)
+			}
+		}
+	}
+	dbg("%s hits %d", state.Name, hits)
+	return hits, nil
+}
+
+// newIssueFromJSON returns the issue described by a JSON object, and the vars
+// available to its message override. It returns a nil issue if the object
+// has invalid fields.
+func newIssueFromJSON(state *linterState, cwd string, object map[string]interface{}) (*Issue, Vars, error) {
+	issue, err := NewIssue(state.Linter.Name, config.formatTemplate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid output format: %s", err)
+	}
+
+	vars := state.vars.Copy()
+	for field := range jsonIssueFields {
+		path := field
+		if val, ok := state.Fields[field]; ok {
+			path = val
+		}
+		value, ok := jsonScalarString(lookupJSONPath(object, path))
+		if !ok {
+			continue
+		}
+		// Some linters, such as gas, report a range of lines as "first-last".
+		if field == "line" || field == "col" {
+			value = strings.SplitN(value, "-", 2)[0]
+		}
+		vars[field] = value
+		if err := setIssueField(state.Linter, issue, cwd, field, value); err != nil {
+			warning("%s reported an invalid %s %q", state.Name, field, value)
+			return nil, nil, nil
+		}
+	}
+	return issue, vars, nil
+}
+
+// lookupJSONPath returns the value at a dot-separated path of object keys
+// within value, or nil if there is none.
+func lookupJSONPath(value interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func jsonScalarString(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	case bool:
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
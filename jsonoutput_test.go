@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessJSONOutput(t *testing.T) {
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+
+	var testcases = []struct {
+		name     string
+		conf     LinterConfig
+		output   string
+		expected []Issue
+	}{
+		{
+			name: "lines",
+			conf: LinterConfig{
+				Format: "json",
+				Fields: map[string]string{"path": "location.file", "line": "location.line", "col": "location.column", "rule": "code"},
+			},
+			output: `{"code":"SA4006","severity":"error","location":{"file":"a.go","line":5,"column":2},"message":"unused value"}
+# warning: not JSON
+{"code":"S1000","severity":"warning","location":{"file":"b.go","line":7,"column":1},"message":"use plain channel send"}
+`,
+			expected: []Issue{
+				{Linter: "lines", Severity: Error, Path: "a.go", Line: 5, Col: 2, Message: "unused value", Rule: "SA4006"},
+				{Linter: "lines", Severity: Warning, Path: "b.go", Line: 7, Col: 1, Message: "use plain channel send", Rule: "S1000"},
+			},
+		},
+		{
+			name: "document",
+			conf: LinterConfig{
+				Format: "json",
+				Issues: "Issues",
+				Fields: map[string]string{"path": "file", "message": "details", "severity": "level"},
+			},
+			output: `{
+  "Issues": [
+    {"file": "c.go", "line": "23-25", "details": "Errors unhandled."},
+    {"file": "d.go", "line": "3", "details": "Use of unsafe calls"}
+  ],
+  "Stats": {"files": 2}
+}`,
+			expected: []Issue{
+				{Linter: "document", Severity: Warning, Path: "c.go", Line: 23, Message: "Errors unhandled."},
+				{Linter: "document", Severity: Warning, Path: "d.go", Line: 3, Message: "Use of unsafe calls"},
+			},
+		},
+	}
+	for _, testcase := range testcases {
+		testcase.conf.Command = "true"
+		linter, err := NewLinter(testcase.name, testcase.conf)
+		require.NoError(t, err)
+		state := &linterState{Linter: linter, issues: make(chan *Issue, 10), vars: Vars{}}
+		processOutput(func(string, ...interface{}) {}, state, []byte(testcase.output))
+		close(state.issues)
+
+		actual := []Issue{}
+		for issue := range state.issues {
+			issue.formatTmpl = nil
+			actual = append(actual, *issue)
+		}
+		assert.Equal(t, testcase.expected, actual, testcase.name)
+	}
+}
+
+func TestNewLinterInvalidFormat(t *testing.T) {
+	_, err := NewLinter("x", LinterConfig{Command: "x", Format: "xml"})
+	assert.EqualError(t, err, `unknown output format "xml"`)
+
+	_, err = NewLinter("x", LinterConfig{Command: "x", Format: "json", Fields: map[string]string{"file": "path"}})
+	assert.EqualError(t, err, `unknown issue field "file" in Fields`)
+}
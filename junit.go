@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// junitPackage returns the package directory a linted path belongs to, in the
+// same form as the directory of an Issue.Path. Import paths, as passed to
+// linters partitioned by package, are resolved to the package's directory.
+func junitPackage(cwd string, path string) string {
+	if strings.HasSuffix(path, ".go") {
+		path = filepath.Dir(path)
+	} else if dir, ok := resolvePackageDir(path); ok {
+		path = dir
+	}
+	if filepath.IsAbs(path) {
+		path = relativePath(cwd, path)
+	}
+	return filepath.Clean(path)
+}
+
+// newJUnitReport builds a JUnit report with one testsuite per package. Each
+// issue is a failed testcase, and each linter that ran over a package without
+// reporting any issues is a passing testcase.
+// nolint: gocyclo
+func newJUnitReport(executions []*linterExecution, issues []*Issue) *junitTestSuites {
+	suites := map[string]*junitTestSuite{}
+	getSuite := func(name string) *junitTestSuite {
+		if suite, ok := suites[name]; ok {
+			return suite
+		}
+		suite := &junitTestSuite{Name: name}
+		suites[name] = suite
+		return suite
+	}
+	// package -> linter -> true if the linter reported an issue in the package
+	failed := map[string]map[string]bool{}
+
+	for _, issue := range issues {
+		if config.Errors && issue.Severity != Error {
+			continue
+		}
+		pkg := filepath.Clean(filepath.Dir(issue.Path))
+		suite := getSuite(pkg)
+		if failed[pkg] == nil {
+			failed[pkg] = map[string]bool{}
+		}
+		for _, linter := range strings.Split(issue.Linter, ", ") {
+			failed[pkg][linter] = true
+		}
+		location := fmt.Sprintf("%s:%d", issue.Path, issue.Line)
+		if issue.Col != 0 {
+			location = fmt.Sprintf("%s:%d", location, issue.Col)
+		}
+		suite.TestCases = append(suite.TestCases, &junitTestCase{
+			Name:      fmt.Sprintf("%s: %s", issue.Linter, location),
+			ClassName: pkg,
+			Failure: &junitFailure{
+				Message:  issue.Message,
+				Type:     string(issue.Severity),
+				Contents: issue.String(),
+			},
+		})
+		suite.Failures++
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		warning("failed to get working directory %s", err)
+	}
+	// package -> linter -> execution error, if any
+	ran := map[string]map[string]error{}
+	for _, execution := range executions {
+		for _, path := range execution.paths {
+			pkg := junitPackage(cwd, path)
+			if ran[pkg] == nil {
+				ran[pkg] = map[string]error{}
+			}
+			if ran[pkg][execution.linter] == nil {
+				ran[pkg][execution.linter] = execution.err
+			}
+		}
+	}
+	for pkg, linters := range ran {
+		names := make([]string, 0, len(linters))
+		for name := range linters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if failed[pkg][name] {
+				continue
+			}
+			suite := getSuite(pkg)
+			testcase := &junitTestCase{Name: name, ClassName: pkg}
+			if err := linters[name]; err != nil {
+				testcase.Error = &junitFailure{
+					Message:  "linter failed",
+					Type:     "error",
+					Contents: err.Error(),
+				}
+				suite.Errors++
+			}
+			suite.TestCases = append(suite.TestCases, testcase)
+		}
+	}
+
+	out := &junitTestSuites{}
+	for _, suite := range suites {
+		suite.Tests = len(suite.TestCases)
+		out.Suites = append(out.Suites, suite)
+	}
+	sort.Sort(junitSuitesByName(out.Suites))
+	return out
+}
+
+type junitSuitesByName []*junitTestSuite
+
+func (s junitSuitesByName) Len() int           { return len(s) }
+func (s junitSuitesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s junitSuitesByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+func outputToJUnit(executions *executionLog, issues chan *Issue) int {
+	// The execution log is only complete once all issues have been received.
+	all := []*Issue{}
+	for issue := range issues {
+		all = append(all, issue)
+	}
+	out := newJUnitReport(executions.Executions(), all)
+	status := 0
+	for _, suite := range out.Suites {
+		if suite.Failures > 0 {
+			status = 1
+		}
+	}
+	d, err := xml.MarshalIndent(out, "", "  ")
+	kingpin.FatalIfError(err, "")
+	fmt.Printf("%s%s\n", xml.Header, d)
+	return status
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJUnitReport(t *testing.T) {
+	executions := []*linterExecution{
+		{linter: "vet", paths: []string{".", "./foo"}},
+		{linter: "golint", paths: []string{"."}},
+		{linter: "golint", paths: []string{"./foo"}},
+		{linter: "gofmt", paths: []string{"foo/a.go", "foo/b.go"}, err: errors.New("boom")},
+	}
+	issues := []*Issue{
+		{Linter: "golint", Severity: Warning, Path: "foo/a.go", Line: 3, Col: 2, Message: "bad"},
+		{Linter: "vet", Severity: Error, Path: "b.go", Line: 1, Message: "worse"},
+	}
+
+	report := newJUnitReport(executions, issues)
+	require.Len(t, report.Suites, 2)
+
+	root := report.Suites[0]
+	assert.Equal(t, ".", root.Name)
+	assert.Equal(t, 2, root.Tests)
+	assert.Equal(t, 1, root.Failures)
+	require.Len(t, root.TestCases, 2)
+	assert.Equal(t, "vet: b.go:1", root.TestCases[0].Name)
+	assert.Equal(t, "worse", root.TestCases[0].Failure.Message)
+	assert.Equal(t, &junitTestCase{Name: "golint", ClassName: "."}, root.TestCases[1])
+
+	foo := report.Suites[1]
+	assert.Equal(t, "foo", foo.Name)
+	assert.Equal(t, 3, foo.Tests)
+	assert.Equal(t, 1, foo.Failures)
+	assert.Equal(t, 1, foo.Errors)
+	require.Len(t, foo.TestCases, 3)
+	assert.Equal(t, "golint: foo/a.go:3:2", foo.TestCases[0].Name)
+	assert.Equal(t, "gofmt", foo.TestCases[1].Name)
+	assert.Equal(t, "boom", foo.TestCases[1].Error.Contents)
+	assert.Equal(t, &junitTestCase{Name: "vet", ClassName: "foo"}, foo.TestCases[2])
+}
+
+func TestNewJUnitReportWithImportPathPartitions(t *testing.T) {
+	defer enableModules(t)()
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkGoMod(t, tmpdir, "module example.com/app\n")
+	mkDir(t, tmpdir, "pkg")
+	executions := []*linterExecution{
+		{linter: "megacheck", paths: []string{"example.com/app/pkg"}},
+		{linter: "golint", paths: []string{"pkg"}},
+	}
+	issues := []*Issue{
+		{Linter: "megacheck", Severity: Warning, Path: "pkg/file.go", Line: 1, Message: "bad"},
+	}
+
+	// The issue and the passing golint run are in the same suite as the
+	// package megacheck was run on.
+	report := newJUnitReport(executions, issues)
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal(t, "pkg", suite.Name)
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	require.Len(t, suite.TestCases, 2)
+	assert.Equal(t, "megacheck: pkg/file.go:1", suite.TestCases[0].Name)
+	assert.Equal(t, &junitTestCase{Name: "golint", ClassName: "pkg"}, suite.TestCases[1])
+}
@@ -12,8 +12,40 @@ import (
 )
 
 type LinterConfig struct {
-	Command           string
-	Pattern           string
+	Command string
+	Pattern string
+	// How the linter's output is parsed: "regex" (the default) to match
+	// Pattern, or "json" to decode it as described by Issues and Fields.
+	Format string
+	// For the "json" format, the dot-separated path of the list of issues in
+	// each JSON value. If empty, each value is an issue or a list of issues.
+	Issues string
+	// For the "json" format, the dot-separated path of each issue field
+	// ("path", "line", "col", "message", "severity" and "rule") within an
+	// issue. Fields default to their own names.
+	Fields map[string]string
+	// Map from severities reported by the linter, ignoring case, to "error"
+	// or "warning". Severities not listed are looked up in severityLevels.
+	Severities map[string]string
+	// The output streams parsed for issues: "combined" (the default), "stdout"
+	// or "stderr". If the linter exits with a non-zero status after writing to
+	// the other stream, it is reported as a failure.
+	Stream string
+	// Report a failure if the linter exits with a non-zero status without
+	// reporting any issues.
+	ReportExitErrors bool
+	// Report issues in generated files, which are otherwise skipped.
+	ReportGenerated bool
+	// If true, the linter's output depends only on the content of the files
+	// it is run over, so only those are hashed when caching it. If unset, the
+	// packages they import are hashed too. If false, it is never cached.
+	Cacheable *bool
+	// Overrides the global Deadline for this linter.
+	Deadline jsonDuration
+	// Command run by --fix to fix the issues reported by the linter, with the
+	// same variables as Command. It is passed the files with issues if the
+	// linter is run on files, otherwise their packages.
+	Fix               string
 	InstallFrom       string
 	PartitionStrategy partitionStrategy
 	IsFast            bool
@@ -31,6 +63,27 @@ func NewLinter(name string, config LinterConfig) (*Linter, error) {
 	if p, ok := predefinedPatterns[config.Pattern]; ok {
 		config.Pattern = p
 	}
+	switch config.Format {
+	case "", "regex":
+	case "json":
+		for field := range config.Fields {
+			if !jsonIssueFields[field] {
+				return nil, fmt.Errorf("unknown issue field %q in Fields", field)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown output format %q", config.Format)
+	}
+	switch config.Stream {
+	case "", "combined", "stdout", "stderr":
+	default:
+		return nil, fmt.Errorf("unknown output stream %q", config.Stream)
+	}
+	for level, severity := range config.Severities {
+		if Severity(severity) != Error && Severity(severity) != Warning {
+			return nil, fmt.Errorf("severity %q for %q must be %q or %q", severity, level, Error, Warning)
+		}
+	}
 	regex, err := regexp.Compile("(?m:" + config.Pattern + ")")
 	if err != nil {
 		return nil, err
@@ -49,12 +102,49 @@ func (l *Linter) String() string {
 	return l.Name
 }
 
+// Severities commonly reported by linters, and the severities they map to.
+// Other severities are treated as warnings.
+var severityLevels = map[string]Severity{
+	"error":    Error,
+	"err":      Error,
+	"fatal":    Error,
+	"critical": Error,
+	"high":     Error,
+	"warning":  Warning,
+	"warn":     Warning,
+	"medium":   Warning,
+	"low":      Warning,
+	"info":     Warning,
+	"note":     Warning,
+}
+
+// severity maps a severity reported by the linter to a gometalinter severity.
+func (l *Linter) severity(level string) Severity {
+	level = strings.ToLower(strings.TrimSpace(level))
+	for from, to := range l.Severities {
+		if strings.ToLower(from) == level {
+			return Severity(to)
+		}
+	}
+	if severity, ok := severityLevels[level]; ok {
+		return severity
+	}
+	return Warning
+}
+
 var predefinedPatterns = map[string]string{
 	"PATH:LINE:COL:MESSAGE": `^(?P<path>.*?\.go):(?P<line>\d+):(?P<col>\d+):\s*(?P<message>.*)$`,
 	"PATH:LINE:MESSAGE":     `^(?P<path>.*?\.go):(?P<line>\d+):\s*(?P<message>.*)$`,
 }
 
 func getLinterByName(name string, overrideConf LinterConfig) *Linter {
+	linter, _ := newLinterByName(name, overrideConf)
+	return linter
+}
+
+// newLinterByName returns the named default linter, overridden by any
+// non-empty fields in overrideConf.
+func newLinterByName(name string, overrideConf LinterConfig) (*Linter, error) {
 	conf := defaultLinters[name]
 	if val := overrideConf.Command; val != "" {
 		conf.Command = val
@@ -62,6 +152,36 @@ func getLinterByName(name string, overrideConf LinterConfig) *Linter {
 	if val := overrideConf.Pattern; val != "" {
 		conf.Pattern = val
 	}
+	if val := overrideConf.Format; val != "" {
+		conf.Format = val
+	}
+	if val := overrideConf.Issues; val != "" {
+		conf.Issues = val
+	}
+	if val := overrideConf.Fields; val != nil {
+		conf.Fields = val
+	}
+	if val := overrideConf.Severities; val != nil {
+		conf.Severities = val
+	}
+	if val := overrideConf.Stream; val != "" {
+		conf.Stream = val
+	}
+	if val := overrideConf.Deadline; val != 0 {
+		conf.Deadline = val
+	}
+	if overrideConf.ReportExitErrors {
+		conf.ReportExitErrors = true
+	}
+	if overrideConf.ReportGenerated {
+		conf.ReportGenerated = true
+	}
+	if val := overrideConf.Cacheable; val != nil {
+		conf.Cacheable = val
+	}
+	if val := overrideConf.Fix; val != "" {
+		conf.Fix = val
+	}
 	if val := overrideConf.InstallFrom; val != "" {
 		conf.InstallFrom = val
 	}
@@ -71,9 +191,7 @@ func getLinterByName(name string, overrideConf LinterConfig) *Linter {
 	if val := overrideConf.PartitionStrategy; val != nil {
 		conf.PartitionStrategy = val
 	}
-
-	linter, _ := NewLinter(name, conf)
-	return linter
+	return NewLinter(name, conf)
 }
 
 func parseLinterConfigSpec(name string, spec string) (LinterConfig, error) {
@@ -190,6 +308,9 @@ func validateLinters(linters map[string]*Linter, config *Config) error {
 	for name := range linters {
 		if _, isDefault := defaultLinters[name]; !isDefault {
 			if _, isCustom := config.Linters[name]; !isCustom {
+				if suggestion := suggestName(name, knownLinterNames(config)); suggestion != "" {
+					name += fmt.Sprintf(" (did you mean %s?)", suggestion)
+				}
 				unknownLinters = append(unknownLinters, name)
 			}
 		}
@@ -202,6 +323,10 @@ func validateLinters(linters map[string]*Linter, config *Config) error {
 
 const vetPattern = `^(?:vet:.*?\.go:\s+(?P<path>.*?\.go):(?P<line>\d+):(?P<col>\d+):\s*(?P<message>.*))|(?:(?P<path>.*?\.go):(?P<line>\d+):\s*(?P<message>.*))$`
 
+// filesOnly is the Cacheable setting of default linters whose output depends
+// only on the files they are run over.
+var filesOnly = true
+
 var defaultLinters = map[string]LinterConfig{
 	"maligned": {
 		Command:           "maligned",
@@ -215,6 +340,7 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `^deadcode: (?P<path>.*?\.go):(?P<line>\d+):(?P<col>\d+):\s*(?P<message>.*)$`,
 		InstallFrom:       "github.com/tsenart/deadcode",
 		PartitionStrategy: partitionPathsAsDirectories,
+		Cacheable:         &filesOnly,
 		defaultEnabled:    true,
 	},
 	"dupl": {
@@ -222,10 +348,11 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `^(?P<path>.*?\.go):(?P<line>\d+)-\d+:\s*(?P<message>.*)$`,
 		InstallFrom:       "github.com/mibk/dupl",
 		PartitionStrategy: partitionPathsAsFiles,
+		Cacheable:         &filesOnly,
 		IsFast:            true,
 	},
 	"errcheck": {
-		Command:           `errcheck -abspath {not_tests=-ignoretests}`,
+		Command:           `errcheck -abspath {not_tests=-ignoretests} {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "github.com/kisielk/errcheck",
 		PartitionStrategy: partitionPathsAsPackages,
@@ -244,6 +371,7 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "github.com/jgautheron/goconst/cmd/goconst",
 		PartitionStrategy: partitionPathsAsDirectories,
+		Cacheable:         &filesOnly,
 		defaultEnabled:    true,
 		IsFast:            true,
 	},
@@ -252,18 +380,22 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `^(?P<cyclo>\d+)\s+\S+\s(?P<function>\S+)\s+(?P<path>.*?\.go):(?P<line>\d+):(\d+)$`,
 		InstallFrom:       "github.com/alecthomas/gocyclo",
 		PartitionStrategy: partitionPathsAsDirectories,
+		Cacheable:         &filesOnly,
 		defaultEnabled:    true,
 		IsFast:            true,
 	},
 	"gofmt": {
 		Command:           `gofmt -l -s`,
 		Pattern:           `^(?P<path>.*?\.go)$`,
+		Fix:               `gofmt -s -w`,
 		PartitionStrategy: partitionPathsAsFiles,
+		Cacheable:         &filesOnly,
 		IsFast:            true,
 	},
 	"goimports": {
 		Command:           `goimports -l`,
 		Pattern:           `^(?P<path>.*?\.go)$`,
+		Fix:               `goimports -w`,
 		InstallFrom:       "golang.org/x/tools/cmd/goimports",
 		PartitionStrategy: partitionPathsAsFiles,
 		IsFast:            true,
@@ -277,7 +409,7 @@ var defaultLinters = map[string]LinterConfig{
 		IsFast:            true,
 	},
 	"gosimple": {
-		Command:           `gosimple`,
+		Command:           `gosimple {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "honnef.co/go/tools/cmd/gosimple",
 		PartitionStrategy: partitionPathsAsPackages,
@@ -287,6 +419,7 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "golang.org/x/tools/cmd/gotype",
 		PartitionStrategy: partitionPathsByDirectory,
+		ReportGenerated:   true,
 		defaultEnabled:    true,
 		IsFast:            true,
 	},
@@ -295,6 +428,7 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "golang.org/x/tools/cmd/gotype",
 		PartitionStrategy: partitionPathsByDirectory,
+		ReportGenerated:   true,
 		defaultEnabled:    true,
 		IsFast:            true,
 	},
@@ -303,6 +437,7 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "github.com/gordonklaus/ineffassign",
 		PartitionStrategy: partitionPathsAsDirectories,
+		Cacheable:         &filesOnly,
 		defaultEnabled:    true,
 		IsFast:            true,
 	},
@@ -318,10 +453,11 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `PATH:LINE:MESSAGE`,
 		InstallFrom:       "github.com/walle/lll/cmd/lll",
 		PartitionStrategy: partitionPathsAsFiles,
+		Cacheable:         &filesOnly,
 		IsFast:            true,
 	},
 	"megacheck": {
-		Command:           `megacheck`,
+		Command:           `megacheck {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "honnef.co/go/tools/cmd/megacheck",
 		PartitionStrategy: partitionPathsAsPackages,
@@ -330,8 +466,10 @@ var defaultLinters = map[string]LinterConfig{
 	"misspell": {
 		Command:           `misspell -j 1`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
+		Fix:               `misspell -w`,
 		InstallFrom:       "github.com/client9/misspell/cmd/misspell",
 		PartitionStrategy: partitionPathsAsFiles,
+		Cacheable:         &filesOnly,
 		IsFast:            true,
 	},
 	"nakedret": {
@@ -339,6 +477,7 @@ var defaultLinters = map[string]LinterConfig{
 		Pattern:           `^(?P<path>.*?\.go):(?P<line>\d+)\s*(?P<message>.*)$`,
 		InstallFrom:       "github.com/alexkohler/nakedret",
 		PartitionStrategy: partitionPathsAsDirectories,
+		Cacheable:         &filesOnly,
 	},
 	"safesql": {
 		Command:           `safesql`,
@@ -347,7 +486,7 @@ var defaultLinters = map[string]LinterConfig{
 		PartitionStrategy: partitionPathsAsPackages,
 	},
 	"staticcheck": {
-		Command:           `staticcheck`,
+		Command:           `staticcheck {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "honnef.co/go/tools/cmd/staticcheck",
 		PartitionStrategy: partitionPathsAsPackages,
@@ -360,18 +499,19 @@ var defaultLinters = map[string]LinterConfig{
 		defaultEnabled:    true,
 	},
 	"test": {
-		Command:           `go test`,
+		Command:           `go test {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `^--- FAIL: .*$\s+(?P<path>.*?\.go):(?P<line>\d+): (?P<message>.*)$`,
 		PartitionStrategy: partitionPathsAsPackages,
 	},
 	"testify": {
-		Command:           `go test`,
+		Command:           `go test {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `Location:\s+(?P<path>.*?\.go):(?P<line>\d+)$\s+Error:\s+(?P<message>[^\n]+)`,
 		PartitionStrategy: partitionPathsAsPackages,
 	},
 	"unconvert": {
 		Command:           `unconvert`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
+		Fix:               `unconvert -apply`,
 		InstallFrom:       "github.com/mdempsky/unconvert",
 		PartitionStrategy: partitionPathsAsPackages,
 		defaultEnabled:    true,
@@ -383,7 +523,7 @@ var defaultLinters = map[string]LinterConfig{
 		PartitionStrategy: partitionPathsAsPackages,
 	},
 	"unused": {
-		Command:           `unused`,
+		Command:           `unused {tags_space=-tags='}{tags_space}{tags_space='}`,
 		Pattern:           `PATH:LINE:COL:MESSAGE`,
 		InstallFrom:       "honnef.co/go/tools/cmd/unused",
 		PartitionStrategy: partitionPathsAsPackages,
@@ -396,7 +536,7 @@ var defaultLinters = map[string]LinterConfig{
 		defaultEnabled:    true,
 	},
 	"vet": {
-		Command:           `govet --no-recurse`,
+		Command:           `govet --no-recurse {tags=-tags=}{tags}`,
 		Pattern:           vetPattern,
 		InstallFrom:       "github.com/dnephin/govet",
 		PartitionStrategy: partitionPathsAsDirectories,
@@ -404,7 +544,7 @@ var defaultLinters = map[string]LinterConfig{
 		IsFast:            true,
 	},
 	"vetshadow": {
-		Command:           `govet --no-recurse --shadow`,
+		Command:           `govet --no-recurse --shadow {tags=-tags=}{tags}`,
 		Pattern:           vetPattern,
 		PartitionStrategy: partitionPathsAsDirectories,
 		defaultEnabled:    true,
@@ -38,6 +38,15 @@ func TestGetLinterByName(t *testing.T) {
 	assert.Equal(t, config.IsFast, overrideConfig.IsFast)
 }
 
+func TestGetLinterByNameCacheable(t *testing.T) {
+	assert.Nil(t, getLinterByName("vet", LinterConfig{}).Cacheable)
+	assert.True(t, *getLinterByName("gofmt", LinterConfig{}).Cacheable)
+
+	disabled := false
+	assert.False(t, *getLinterByName("gofmt", LinterConfig{Cacheable: &disabled}).Cacheable)
+	assert.True(t, *getLinterByName("gofmt", LinterConfig{}).Cacheable)
+}
+
 func TestValidateLinters(t *testing.T) {
 	originalConfig := *config
 	defer func() { config = &originalConfig }()
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes used by the Language Server Protocol.
+const (
+	lspParseError     = -32700
+	lspInvalidRequest = -32600
+	lspMethodNotFound = -32601
+)
+
+// LSP diagnostic severities.
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+)
+
+type lspRequest struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+type lspErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   *lspError        `json:"error"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type lspTextDocumentParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string           `json:"uri"`
+	Diagnostics []*lspDiagnostic `json:"diagnostics"`
+}
+
+// lspServer implements enough of the Language Server Protocol to publish
+// diagnostics for each package as its files are opened and saved.
+type lspServer struct {
+	linters map[string]*Linter
+	exclude *regexp.Regexp
+	include *regexp.Regexp
+
+	writeLock sync.Mutex
+	out       io.Writer
+
+	lock     sync.Mutex
+	shutdown bool
+	// Cancels the linters currently running for each package directory.
+	running map[string]chan struct{}
+	// Documents in each package directory with published diagnostics.
+	published map[string]map[string]bool
+}
+
+func newLSPServer(linters map[string]*Linter, exclude, include *regexp.Regexp, out io.Writer) *lspServer {
+	return &lspServer{
+		linters:   linters,
+		exclude:   exclude,
+		include:   include,
+		out:       out,
+		running:   map[string]chan struct{}{},
+		published: map[string]map[string]bool{},
+	}
+}
+
+// serve handles messages from r until the client sends "exit". An error is
+// returned if the client exits without first requesting shutdown.
+func (s *lspServer) serve(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		body, err := readLSPMessage(reader)
+		if err != nil {
+			return err
+		}
+		req := &lspRequest{}
+		if err := json.Unmarshal(body, req); err != nil {
+			s.replyError(nil, lspParseError, err.Error())
+			continue
+		}
+		if req.Method == "exit" {
+			s.lock.Lock()
+			shutdown := s.shutdown
+			s.lock.Unlock()
+			if !shutdown {
+				return errors.New("exit requested without shutdown")
+			}
+			return nil
+		}
+		s.handle(req)
+	}
+}
+
+func (s *lspServer) handle(req *lspRequest) {
+	debug("lsp: received %s", req.Method)
+	s.lock.Lock()
+	shutdown := s.shutdown
+	s.lock.Unlock()
+	if shutdown {
+		if req.ID != nil {
+			s.replyError(req.ID, lspInvalidRequest, "server is shutting down")
+		}
+		return
+	}
+
+	switch req.Method {
+	case "initialize":
+		s.reply(req.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    0,
+					"save":      map[string]interface{}{"includeText": false},
+				},
+			},
+			"serverInfo": map[string]interface{}{"name": "gometalinter"},
+		})
+
+	case "textDocument/didOpen", "textDocument/didSave":
+		params := &lspTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			warning("lsp: invalid %s params: %s", req.Method, err)
+			return
+		}
+		path, err := lspURIToPath(params.TextDocument.URI)
+		if err != nil {
+			warning("lsp: %s", err)
+			return
+		}
+		s.lint(filepath.Dir(path))
+
+	case "shutdown":
+		s.lock.Lock()
+		s.shutdown = true
+		for dir, cancel := range s.running {
+			close(cancel)
+			delete(s.running, dir)
+		}
+		s.lock.Unlock()
+		s.reply(req.ID, nil)
+
+	default:
+		// Notifications we don't handle, such as didChange, are ignored.
+		if req.ID != nil {
+			s.replyError(req.ID, lspMethodNotFound, fmt.Sprintf("method %q not supported", req.Method))
+		}
+	}
+}
+
+// lint runs the linters over the package in dir and publishes diagnostics for
+// it, cancelling any run already in progress for the same package.
+func (s *lspServer) lint(dir string) {
+	s.lock.Lock()
+	if cancel, ok := s.running[dir]; ok {
+		close(cancel)
+	}
+	cancel := make(chan struct{})
+	s.running[dir] = cancel
+	s.lock.Unlock()
+
+	go func() {
+		debug("lsp: linting %s", dir)
+		issues, errch, _ := runLinters(s.linters, []string{lspLintPath(dir)}, config.Concurrency, s.exclude, s.include, cancel)
+		diagnostics := map[string][]*lspDiagnostic{}
+		for issue := range issues {
+			if config.Errors && issue.Severity != Error {
+				continue
+			}
+			path, err := filepath.Abs(issue.Path)
+			if err != nil {
+				warning("lsp: %s", err)
+				continue
+			}
+			uri := lspPathToURI(path)
+			diagnostics[uri] = append(diagnostics[uri], newLSPDiagnostic(issue))
+		}
+		for err := range errch {
+			select {
+			case <-cancel:
+			default:
+				warning("%s", err)
+			}
+		}
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if s.running[dir] != cancel {
+			// Superseded by a later run, or shut down.
+			return
+		}
+		delete(s.running, dir)
+		// Clear diagnostics from documents which no longer have any.
+		for uri := range s.published[dir] {
+			if _, ok := diagnostics[uri]; !ok {
+				diagnostics[uri] = []*lspDiagnostic{}
+			}
+		}
+		s.published[dir] = map[string]bool{}
+		uris := make([]string, 0, len(diagnostics))
+		for uri, documentDiagnostics := range diagnostics {
+			uris = append(uris, uri)
+			if len(documentDiagnostics) > 0 {
+				s.published[dir][uri] = true
+			}
+		}
+		sort.Strings(uris)
+		for _, uri := range uris {
+			s.notify("textDocument/publishDiagnostics", &lspPublishDiagnosticsParams{
+				URI:         uri,
+				Diagnostics: diagnostics[uri],
+			})
+		}
+	}()
+}
+
+func newLSPDiagnostic(issue *Issue) *lspDiagnostic {
+	position := lspPosition{}
+	if issue.Line > 0 {
+		position.Line = issue.Line - 1
+	}
+	if issue.Col > 0 {
+		position.Character = issue.Col - 1
+	}
+	return &lspDiagnostic{
+		Range:    lspRange{Start: position, End: position},
+		Severity: lspSeverity(issue.Severity),
+		Code:     issue.Rule,
+		Source:   issue.Linter,
+		Message:  issue.Message,
+	}
+}
+
+func lspSeverity(severity Severity) int {
+	switch severity {
+	case Error:
+		return lspSeverityError
+	case Warning:
+		return lspSeverityWarning
+	default:
+		return lspSeverityInformation
+	}
+}
+
+// lspLintPath returns the path to pass to the linters for dir, which is
+// relative to the working directory if possible.
+func lspLintPath(dir string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(cwd, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return dir
+	}
+	return relativePackagePath(rel)
+}
+
+func lspURIToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI %q", uri)
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path), nil
+}
+
+func lspPathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func (s *lspServer) reply(id *json.RawMessage, result interface{}) {
+	s.write(&lspResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *lspServer) replyError(id *json.RawMessage, code int, message string) {
+	s.write(&lspErrorResponse{JSONRPC: "2.0", ID: id, Error: &lspError{Code: code, Message: message}})
+}
+
+func (s *lspServer) notify(method string, params interface{}) {
+	s.write(&lspNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *lspServer) write(message interface{}) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	if err := writeLSPMessage(s.out, message); err != nil {
+		warning("lsp: failed to write message: %s", err)
+	}
+}
+
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return body, err
+}
+
+func writeLSPMessage(w io.Writer, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSPMessageRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeLSPMessage(buf, map[string]string{"method": "initialized"}))
+	assert.Equal(t, "Content-Length: 24\r\n\r\n{\"method\":\"initialized\"}", buf.String())
+
+	body, err := readLSPMessage(bufio.NewReader(buf))
+	require.NoError(t, err)
+	assert.Equal(t, `{"method":"initialized"}`, string(body))
+}
+
+func TestLSPURIs(t *testing.T) {
+	uri := lspPathToURI("/tmp/some dir/file.go")
+	assert.Equal(t, "file:///tmp/some%20dir/file.go", uri)
+	path, err := lspURIToPath(uri)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/tmp/some dir/file.go"), path)
+
+	_, err = lspURIToPath("untitled:Untitled-1")
+	assert.Error(t, err)
+}
+
+func TestNewLSPDiagnostic(t *testing.T) {
+	issue := &Issue{Linter: "vet", Severity: Error, Path: "file.go", Line: 3, Col: 5, Message: "bad"}
+	assert.Equal(t, &lspDiagnostic{
+		Range:    lspRange{Start: lspPosition{Line: 2, Character: 4}, End: lspPosition{Line: 2, Character: 4}},
+		Severity: lspSeverityError,
+		Source:   "vet",
+		Message:  "bad",
+	}, newLSPDiagnostic(issue))
+}
+
+func TestLSPServerPublishesDiagnostics(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+
+	mkDir(t, tmpdir, "pkg")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$1/file.go:2: found it\"\n"), 0755)
+	require.NoError(t, err)
+	linterConfig, err := parseLinterConfigSpec("fake", script+":PATH:LINE:MESSAGE")
+	require.NoError(t, err)
+	linters := map[string]*Linter{"fake": getLinterByName("fake", linterConfig)}
+
+	in, clientOut := io.Pipe()
+	clientIn, out := io.Pipe()
+	server := newLSPServer(linters, nil, nil, out)
+	done := make(chan error, 1)
+	go func() { done <- server.serve(in) }()
+
+	send := func(message string) {
+		require.NoError(t, writeLSPMessage(clientOut, json.RawMessage(message)))
+	}
+	reader := bufio.NewReader(clientIn)
+	receive := func() map[string]interface{} {
+		body, err := readLSPMessage(reader)
+		require.NoError(t, err)
+		message := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(body, &message))
+		return message
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	assert.Contains(t, receive(), "result")
+
+	uri := lspPathToURI(filepath.Join(tmpdir, "pkg", "file.go"))
+	send(`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"` + uri + `"}}}`)
+	published := receive()
+	assert.Equal(t, "textDocument/publishDiagnostics", published["method"])
+	params := published["params"].(map[string]interface{})
+	diagnostics := params["diagnostics"].([]interface{})
+	require.Len(t, diagnostics, 1)
+	diagnostic := diagnostics[0].(map[string]interface{})
+	assert.Equal(t, "fake", diagnostic["source"])
+	assert.Equal(t, "found it", diagnostic["message"])
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`)
+	assert.Contains(t, receive(), "result")
+	send(`{"jsonrpc":"2.0","method":"exit"}`)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for exit")
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"os/user"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"sort"
 	"strings"
-	"text/template"
+	"syscall"
 	"time"
 
 	kingpin "gopkg.in/alecthomas/kingpin.v3-unstable"
@@ -26,7 +27,8 @@ var (
 )
 
 func setupFlags(app *kingpin.Application) {
-	app.Flag("config", "Load JSON configuration from file.").Envar("GOMETALINTER_CONFIG").Action(loadConfig).String()
+	app.Flag("config", "Load JSON, YAML or TOML configuration from file.").Envar("GOMETALINTER_CONFIG").Action(loadConfig).String()
+	app.Flag("no-config", "Do not load $GOMETALINTER_CONFIG or search for a configuration file.").Bool()
 	app.Flag("disable", "Disable previously enabled linters.").PlaceHolder("LINTER").Short('D').Action(disableAction).Strings()
 	app.Flag("enable", "Enable previously disabled linters.").PlaceHolder("LINTER").Short('E').Action(enableAction).Strings()
 	app.Flag("linter", "Define a linter.").PlaceHolder("NAME:COMMAND:PATTERN").Action(cliLinterOverrides).StringMap()
@@ -34,7 +36,7 @@ func setupFlags(app *kingpin.Application) {
 	app.Flag("severity", "Map of linter severities.").PlaceHolder("LINTER:SEVERITY").StringMapVar(&config.Severity)
 	app.Flag("disable-all", "Disable all linters.").Action(disableAllAction).Bool()
 	app.Flag("enable-all", "Enable all linters.").Action(enableAllAction).Bool()
-	app.Flag("format", "Output format.").PlaceHolder(config.Format).StringVar(&config.Format)
+	app.Flag("format", fmt.Sprintf("Output format, either a template or one of %s.", strings.Join(issueFormatPresetNames(), ", "))).PlaceHolder(config.Format).StringVar(&config.Format)
 	app.Flag("vendored-linters", "Use vendored linters (recommended).").BoolVar(&config.VendoredLinters)
 	app.Flag("fast", "Only run fast linters.").BoolVar(&config.Fast)
 	app.Flag("install", "Attempt to install all known linters.").Short('i').BoolVar(&config.Install)
@@ -45,7 +47,8 @@ func setupFlags(app *kingpin.Application) {
 	app.Flag("concurrency", "Number of concurrent linters to run.").PlaceHolder(fmt.Sprintf("%d", runtime.NumCPU())).Short('j').IntVar(&config.Concurrency)
 	app.Flag("exclude", "Exclude messages matching these regular expressions.").Short('e').PlaceHolder("REGEXP").StringsVar(&config.Exclude)
 	app.Flag("include", "Include messages matching these regular expressions.").Short('I').PlaceHolder("REGEXP").StringsVar(&config.Include)
-	app.Flag("skip", "Skip directories with this name when expanding '...'.").Short('s').PlaceHolder("DIR...").StringsVar(&config.Skip)
+	app.Flag("skip", "Skip directories and files with this name, or matching this glob, when expanding '...'. Issues in matching files are also skipped.").Short('s').PlaceHolder("DIR...").StringsVar(&config.Skip)
+	app.Flag("gitignore", "Skip directories and files ignored by .gitignore or .git/info/exclude when expanding '...', and issues in ignored files.").BoolVar(&config.GitIgnore)
 	app.Flag("vendor", "Enable vendoring support (skips 'vendor' directories and sets GO15VENDOREXPERIMENT=1).").BoolVar(&config.Vendor)
 	app.Flag("cyclo-over", "Report functions with cyclomatic complexity over N (using gocyclo).").PlaceHolder("10").IntVar(&config.Cyclo)
 	app.Flag("line-length", "Report lines longer than N (using lll).").PlaceHolder("80").IntVar(&config.LineLength)
@@ -55,13 +58,32 @@ func setupFlags(app *kingpin.Application) {
 	app.Flag("dupl-threshold", "Minimum token sequence as a clone for dupl.").PlaceHolder("50").IntVar(&config.DuplThreshold)
 	app.Flag("sort", fmt.Sprintf("Sort output by any of %s.", strings.Join(sortKeys, ", "))).PlaceHolder("none").EnumsVar(&config.Sort, sortKeys...)
 	app.Flag("tests", "Include test files for linters that support this option.").Short('t').BoolVar(&config.Test)
+	app.Flag("tags", "Build tags passed to linters that support them.").PlaceHolder("TAG,...").StringsVar(&config.Tags)
+	app.Flag("matrix", "Run linters under each of these build contexts, merging the results.").PlaceHolder("GOOS/GOARCH[:TAG,...]").StringsVar(&config.Matrix)
 	app.Flag("deadline", "Cancel linters if they have not completed within this duration.").PlaceHolder("30s").DurationVar((*time.Duration)(&config.Deadline))
+	app.Flag("total-deadline", "Cancel linters still running when the whole run has not completed within this duration.").PlaceHolder("0s").DurationVar((*time.Duration)(&config.TotalDeadline))
 	app.Flag("errors", "Only show errors.").BoolVar(&config.Errors)
 	app.Flag("json", "Generate structured JSON rather than standard line-based output.").BoolVar(&config.JSON)
 	app.Flag("checkstyle", "Generate checkstyle XML rather than standard line-based output.").BoolVar(&config.Checkstyle)
+	app.Flag("sarif", "Generate SARIF 2.1 JSON rather than standard line-based output.").BoolVar(&config.SARIF)
+	app.Flag("junit", "Generate JUnit XML rather than standard line-based output.").BoolVar(&config.JUnit)
+	app.Flag("gitlab", "Generate GitLab Code Quality JSON rather than standard line-based output.").BoolVar(&config.GitLab)
 	app.Flag("enable-gc", "Enable GC for linters (useful on large repositories).").BoolVar(&config.EnableGC)
 	app.Flag("aggregate", "Aggregate issues reported by several linters.").BoolVar(&config.Aggregate)
+	app.Flag("skip-generated", "Skip issues in generated files (use --no-skip-generated to report them).").BoolVar(&config.SkipGenerated)
+	app.Flag("generated-marker", "Treat files with a comment containing this text before the package clause as generated.").PlaceHolder("TEXT").StringsVar(&config.GeneratedMarkers)
 	app.Flag("warn-unmatched-nolint", "Warn if a nolint directive is not matched with an issue.").BoolVar(&config.WarnUnmatchedDirective)
+	app.Flag("new-from-rev", "Only report issues on lines added or modified since this git revision.").PlaceHolder("REV").StringVar(&config.NewFromRev)
+	app.Flag("new-from-patch", "Only report issues on lines added or modified by this unified diff.").PlaceHolder("FILE").StringVar(&config.NewFromPatch)
+	app.Flag("new-file-issues", "With --new-from-rev or --new-from-patch, also report issues for whole files (eg. from gofmt) if the file was modified.").BoolVar(&config.NewFileIssues)
+	app.Flag("baseline", "Suppress issues recorded in this baseline file.").PlaceHolder("FILE").StringVar(&config.Baseline)
+	app.Flag("write-baseline", "Record all current issues in this baseline file.").PlaceHolder("FILE").StringVar(&config.WriteBaseline)
+	app.Flag("fix", "Fix issues using the fix command of each linter that supports it, then lint again.").BoolVar(&config.Fix)
+	app.Flag("dry-run", "With --fix, output the fixes as a unified diff rather than applying them.").BoolVar(&config.DryRun)
+	app.Flag("watch", "Watch paths and re-lint packages whenever their files change.").BoolVar(&config.Watch)
+	app.Flag("cache", "Cache linter results and reuse them when the linted files are unchanged.").BoolVar(&config.Cache)
+	app.Flag("cache-dir", "Directory to store cached linter results in.").PlaceHolder(config.CacheDir).StringVar(&config.CacheDir)
+	app.Flag("cache-size", "Maximum size of the result cache in megabytes.").PlaceHolder(fmt.Sprintf("%d", config.CacheSize)).IntVar(&config.CacheSize)
 	app.GetFlag("help").Short('h')
 }
 
@@ -82,24 +104,38 @@ func cliLinterOverrides(app *kingpin.Application, element *kingpin.ParseElement,
 }
 
 func loadConfig(app *kingpin.Application, element *kingpin.ParseElement, ctx *kingpin.ParseContext) error {
-	r, err := os.Open(*element.Value)
+	return loadConfigFile(*element.Value)
+}
+
+// loadDefaultConfig loads the configuration file named by $GOMETALINTER_CONFIG
+// or, failing that, the closest configuration file found by searching upwards
+// from the working directory. Neither is loaded if args contain --config or
+// --no-config.
+func loadDefaultConfig(app *kingpin.Application, args []string) error {
+	context, err := app.ParseContext(args)
 	if err != nil {
-		return err
+		// Reported when the arguments are parsed for real.
+		return nil
+	}
+	flags := context.Elements.FlagMap()
+	if flags["config"] != nil || flags["no-config"] != nil {
+		return nil
 	}
-	defer r.Close() // nolint: errcheck
-	err = json.NewDecoder(r).Decode(config)
+	if path := os.Getenv("GOMETALINTER_CONFIG"); path != "" {
+		return loadConfigFile(path)
+	}
+	cwd, err := os.Getwd()
 	if err != nil {
 		return err
 	}
-	for _, disable := range config.Disable {
-		for i, enable := range config.Enable {
-			if enable == disable {
-				config.Enable = append(config.Enable[:i], config.Enable[i+1:]...)
-				break
-			}
-		}
+	if path := findConfigFile(cwd); path != "" {
+		return loadConfigFile(path)
 	}
-	return err
+	return nil
+}
+
+func loadConfigFile(path string) error {
+	return newConfigLoader(config).load(path)
 }
 
 func disableAction(app *kingpin.Application, element *kingpin.ParseElement, ctx *kingpin.ParseContext) error {
@@ -171,9 +207,20 @@ func formatSeverity() string {
 }
 
 func main() {
-	pathsArg := kingpin.Arg("path", "Directories to lint. Defaults to \".\". <path>/... will recurse.").Strings()
 	app := kingpin.CommandLine
 	setupFlags(app)
+	lintCmd := app.Command("lint", "Lint paths (the default command).").Default()
+	pathsArg := lintCmd.Arg("path", "Directories to lint. Defaults to \".\". <path>/... will recurse.").Strings()
+	serveCmd := app.Command("serve", "Run a daemon that lints paths on behalf of \"client\".")
+	serveSocket := serveCmd.Flag("socket", "Unix socket to listen on.").Required().String()
+	clientCmd := app.Command("client", "Lint paths using a daemon started with \"serve\". Accepts the same flags as \"lint\".")
+	clientSocket := clientCmd.Flag("socket", "Unix socket the daemon is listening on.").Envar("GOMETALINTER_SOCKET").Required().String()
+	clientCmd.Arg("path", "Directories to lint. Defaults to \".\". <path>/... will recurse.").Strings()
+	configCmd := app.Command("config", "Print or validate the effective configuration.")
+	configPrintCmd := configCmd.Command("print", "Print the effective configuration and the linters it enables.").Default()
+	configOutput := configPrintCmd.Flag("output", "Output format.").Default("json").Enum("json", "yaml")
+	configCmd.Command("validate", "Check the configuration, exiting non-zero if it is invalid.")
+	app.Command("lsp", "Run a Language Server Protocol server over stdio, publishing diagnostics for packages as they are opened and saved.")
 	app.Help = fmt.Sprintf(`Aggregate and normalise the output of a whole bunch of Go linters.
 
 PlaceHolder linters:
@@ -184,7 +231,39 @@ Severity override map (default is "warning"):
 
 %s
 `, formatLinters(), formatSeverity())
-	kingpin.Parse()
+	err := loadDefaultConfig(app, os.Args[1:])
+	kingpin.FatalIfError(err, "failed to load configuration")
+	command := kingpin.Parse()
+	if config.configFile != "" {
+		debug("loaded configuration from %s", config.configFile)
+		debugConfigSources(config)
+	}
+
+	switch command {
+	case "serve":
+		configureEnvironment()
+		err := serve(*serveSocket)
+		kingpin.FatalIfError(err, "")
+		return
+
+	case "client":
+		os.Exit(runClient(*clientSocket, clientArgs(os.Args[1:])))
+
+	case "config print":
+		err := printConfig(os.Stdout, config, lintersFromConfig(config), *configOutput)
+		kingpin.FatalIfError(err, "")
+		return
+
+	case "config validate":
+		errs := validateConfig(config)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
 	if config.Install {
 		if config.VendoredLinters {
@@ -201,15 +280,78 @@ Severity override map (default is "warning"):
 	paths := resolvePaths(*pathsArg, config.Skip)
 
 	linters := lintersFromConfig(config)
-	err := validateLinters(linters, config)
+	err = validateLinters(linters, config)
 	kingpin.FatalIfError(err, "")
 
-	issues, errch := runLinters(linters, paths, config.Concurrency, exclude, include)
+	if command == "lsp" {
+		handleInterrupts(true)
+		err := newLSPServer(linters, exclude, include, os.Stdout).serve(os.Stdin)
+		kingpin.FatalIfError(err, "")
+		return
+	}
+
+	if config.DryRun && !config.Fix {
+		kingpin.Fatalf("--dry-run can only be used with --fix")
+	}
+	if config.Fix {
+		if config.Watch {
+			kingpin.Fatalf("--fix can not be used with --watch")
+		}
+		handleInterrupts(true)
+		os.Exit(fixIssues(os.Stdout, linters, paths, exclude, include))
+	}
+
+	if config.Watch {
+		if config.WriteBaseline != "" {
+			kingpin.Fatalf("--write-baseline can not be used with --watch")
+		}
+		handleInterrupts(true)
+		err := watch(linters, *pathsArg, exclude, include)
+		kingpin.FatalIfError(err, "")
+		return
+	}
+
+	handleInterrupts(false)
+	issues, errch, executions := runLinters(linters, paths, config.Concurrency, exclude, include, nil)
+	status := outputIssues(linters, executions, issues, errch)
+	elapsed := time.Since(start)
+	debug("total elapsed time %s", elapsed)
+	os.Exit(status)
+}
+
+// handleInterrupts terminates running linters when gometalinter receives
+// SIGINT or SIGTERM. Linters run in their own process groups, so would
+// otherwise be left running. If exit is false, the issues reported so far are
+// output as usual once the linters have exited. A second signal exits
+// immediately.
+func handleInterrupts(exit bool) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		warning("interrupted, waiting for linters to exit")
+		runningLinters.interrupt()
+		if exit {
+			os.Exit(1)
+		}
+	}()
+}
+
+// outputIssues writes issues in the configured output format and returns the
+// exit status.
+func outputIssues(linters map[string]*Linter, executions *executionLog, issues chan *Issue, errch chan error) int {
 	status := 0
 	if config.JSON {
-		status |= outputToJSON(issues)
+		status |= outputToJSON(executions, issues)
 	} else if config.Checkstyle {
 		status |= outputToCheckstyle(issues)
+	} else if config.SARIF {
+		status |= outputToSARIF(linters, issues)
+	} else if config.JUnit {
+		status |= outputToJUnit(executions, issues)
+	} else if config.GitLab {
+		status |= outputToGitLab(issues)
 	} else {
 		status |= outputToConsole(issues)
 	}
@@ -217,14 +359,19 @@ Severity override map (default is "warning"):
 		warning("%s", err)
 		status |= 2
 	}
-	elapsed := time.Since(start)
-	debug("total elapsed time %s", elapsed)
-	os.Exit(status)
+	if timeouts := executions.timeouts(); len(timeouts) > 0 {
+		names := []string{}
+		for _, timeout := range timeouts {
+			names = append(names, timeout.Linter)
+		}
+		warning("linters timed out, their issues may be incomplete: %s", strings.Join(names, ", "))
+	}
+	return status
 }
 
 // nolint: gocyclo
 func processConfig(config *Config) (include *regexp.Regexp, exclude *regexp.Regexp) {
-	tmpl, err := template.New("output").Parse(config.Format)
+	tmpl, err := newIssueFormatTemplate(config.Format)
 	kingpin.FatalIfError(err, "invalid format %q", config.Format)
 	config.formatTemplate = tmpl
 
@@ -262,6 +409,26 @@ https://github.com/alecthomas/gometalinter/issues/new
 		exclude = regexp.MustCompile(strings.Join(config.Exclude, "|"))
 	}
 
+	switch {
+	case config.NewFromRev != "":
+		config.changedLines, err = loadChangedLinesFromRev(config.NewFromRev)
+		kingpin.FatalIfError(err, "failed to determine lines changed since %s", config.NewFromRev)
+	case config.NewFromPatch != "":
+		config.changedLines, err = loadChangedLinesFromPatch(config.NewFromPatch)
+		kingpin.FatalIfError(err, "failed to load patch %s", config.NewFromPatch)
+	}
+	if config.Baseline != "" {
+		config.baseline, err = loadBaseline(config.Baseline)
+		kingpin.FatalIfError(err, "failed to load baseline")
+	}
+
+	config.excludeRules, err = compileExcludeRules(config.ExcludeRules)
+	kingpin.FatalIfError(err, "invalid ExcludeRules")
+	_, err = buildContexts(config)
+	kingpin.FatalIfError(err, "invalid Matrix")
+	err = validateOverrides(config)
+	kingpin.FatalIfError(err, "invalid Overrides")
+
 	if len(config.Include) > 0 {
 		include = regexp.MustCompile(strings.Join(config.Include, "|"))
 	}
@@ -282,13 +449,12 @@ func outputToConsole(issues chan *Issue) int {
 	return status
 }
 
-func outputToJSON(issues chan *Issue) int {
+// outputToJSON writes issues as a JSON array. Linters that timed out are
+// listed after the issues, as errors without a path.
+func outputToJSON(executions *executionLog, issues chan *Issue) int {
 	fmt.Println("[")
 	status := 0
-	for issue := range issues {
-		if config.Errors && issue.Severity != Error {
-			continue
-		}
+	output := func(issue *Issue) {
 		if status != 0 {
 			fmt.Printf(",\n")
 		}
@@ -297,63 +463,179 @@ func outputToJSON(issues chan *Issue) int {
 		fmt.Printf("  %s", d)
 		status = 1
 	}
+	for issue := range issues {
+		if config.Errors && issue.Severity != Error {
+			continue
+		}
+		output(issue)
+	}
+	for _, timeout := range executions.timeouts() {
+		output(&Issue{Linter: timeout.Linter, Severity: Error, Message: timeout.Error()})
+	}
 	fmt.Printf("\n]\n")
 	return status
 }
 
 func resolvePaths(paths, skip []string) []string {
+	packages, _ := resolvePackagesAndDirs(paths, skip)
+	for _, d := range packages {
+		debug("linting path %s", d)
+	}
+	return packages
+}
+
+// resolvePackagesAndDirs returns the package directories to lint for paths,
+// and every directory that may contain a package, including directories
+// beneath "/..." paths that do not yet contain any Go files.
+func resolvePackagesAndDirs(paths, skip []string) ([]string, []string) {
 	if len(paths) == 0 {
-		return []string{"."}
+		return []string{"."}, []string{"."}
 	}
 
 	skipPath := newPathFilter(skip)
+	var ignore *gitIgnore
+	if config.GitIgnore {
+		ignore = newGitIgnore()
+	}
+	packages := newStringSet()
 	dirs := newStringSet()
 	for _, path := range paths {
 		if strings.HasSuffix(path, "/...") {
-			root := filepath.Dir(path)
+			root := resolveImportPath(filepath.Dir(path))
 			_ = filepath.Walk(root, func(p string, i os.FileInfo, err error) error {
 				if err != nil {
 					warning("invalid path %q: %s", p, err)
 					return err
 				}
 
-				skip := skipPath(p)
+				skip := skipPath(p) || (ignore != nil && p != root && ignore.ignored(p, i.IsDir()))
 				switch {
 				case i.IsDir() && skip:
 					return filepath.SkipDir
-				case !i.IsDir() && !skip && strings.HasSuffix(p, ".go"):
-					dirs.add(filepath.Clean(filepath.Dir(p)))
+				case i.IsDir() && p != root && modulesEnabled() && isModuleRoot(p):
+					// Nested modules are linted separately.
+					return filepath.SkipDir
+				case i.IsDir():
+					dirs.add(filepath.Clean(p))
+				case !skip && strings.HasSuffix(p, ".go"):
+					packages.add(filepath.Clean(filepath.Dir(p)))
 				}
 				return nil
 			})
 		} else {
-			dirs.add(filepath.Clean(path))
+			dir := filepath.Clean(resolveImportPath(path))
+			packages.add(dir)
+			dirs.add(dir)
 		}
 	}
+	return relativePackagePaths(packages), relativePackagePaths(dirs)
+}
+
+func relativePackagePaths(dirs *stringSet) []string {
 	out := make([]string, 0, dirs.size())
 	for _, d := range dirs.asSlice() {
 		out = append(out, relativePackagePath(d))
 	}
 	sort.Strings(out)
-	for _, d := range out {
-		debug("linting path %s", d)
-	}
 	return out
 }
 
 func newPathFilter(skip []string) func(string) bool {
-	filter := map[string]bool{}
-	for _, name := range skip {
-		filter[name] = true
+	matches := newSkipMatcher(skip)
+	return func(path string) bool {
+		if matches(path) {
+			return true
+		}
+		base := filepath.Base(path)
+		return base != "." && base != ".." && strings.ContainsAny(base[0:1], "_.")
+	}
+}
+
+// newSkipMatcher returns a function that reports whether a path matches any of
+// the --skip entries. Entries are names matched against the base name or the
+// whole path, or globs if they contain any of "*?[". Globs containing a "/"
+// are matched against the whole path relative to the working directory, where
+// "**" matches any number of directories, and other globs against the base
+// name.
+func newSkipMatcher(skip []string) func(string) bool {
+	names := map[string]bool{}
+	globs := []string{}
+	for _, entry := range skip {
+		if strings.ContainsAny(entry, "*?[") {
+			globs = append(globs, filepath.ToSlash(entry))
+		} else {
+			names[entry] = true
+		}
 	}
 
 	return func(path string) bool {
 		base := filepath.Base(path)
-		if filter[base] || filter[path] {
+		if names[base] || names[path] {
 			return true
 		}
-		return base != "." && base != ".." && strings.ContainsAny(base[0:1], "_.")
+		if len(globs) == 0 {
+			return false
+		}
+		name := filepath.ToSlash(relativeToWorkingDir(path))
+		for _, glob := range globs {
+			if !strings.Contains(glob, "/") {
+				if ok, _ := filepath.Match(glob, base); ok {
+					return true
+				}
+			} else if matchPathGlob(glob, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// relativeToWorkingDir returns a cleaned path, relative to the working
+// directory if it is within it.
+func relativeToWorkingDir(path string) string {
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
 	}
+	return rel
+}
+
+// filterSkippedFiles suppresses issues in files that match skip, or that are
+// ignored by git if ignore is not nil, so that single files can be skipped in
+// packages that are otherwise linted.
+func filterSkippedFiles(skip []string, ignore *gitIgnore, issues chan *Issue) chan *Issue {
+	matches := newSkipMatcher(skip)
+	out := make(chan *Issue, 1000000)
+	go func() {
+		for issue := range issues {
+			if issue.Path != "" && (matches(issue.Path) || (ignore != nil && ignore.fileIgnored(issue.Path))) {
+				debug("ignoring issue in skipped file %s", issue)
+				continue
+			}
+			out <- issue
+		}
+		close(out)
+	}()
+	return out
+}
+
+func maybeFilterSkippedFiles(issues chan *Issue) chan *Issue {
+	var ignore *gitIgnore
+	if config.GitIgnore {
+		ignore = newGitIgnore()
+	}
+	if len(config.Skip) == 0 && ignore == nil {
+		return issues
+	}
+	return filterSkippedFiles(config.Skip, ignore, issues)
 }
 
 func relativePackagePath(dir string) string {
@@ -368,7 +650,11 @@ func lintersFromConfig(config *Config) map[string]*Linter {
 	out := map[string]*Linter{}
 	config.Enable = replaceWithMegacheck(config.Enable, config.EnableAll)
 	for _, name := range config.Enable {
-		linter := getLinterByName(name, LinterConfig(config.Linters[name]))
+		linter, err := newLinterByName(name, LinterConfig(config.Linters[name]))
+		if err != nil {
+			warning("invalid linter %s: %s", name, err)
+			continue
+		}
 		if config.Fast && !linter.IsFast {
 			continue
 		}
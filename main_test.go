@@ -80,6 +80,19 @@ func TestResolvePathsWithExpands(t *testing.T) {
 	assert.Equal(t, expected, paths)
 }
 
+func TestResolvePackagesAndDirs(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "pkg", "a")
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpdir, "pkg", "empty"), 0755))
+	mkDir(t, tmpdir, "_exclude")
+
+	packages, dirs := resolvePackagesAndDirs([]string{"./..."}, nil)
+	assert.Equal(t, []string{"./pkg/a"}, packages)
+	assert.Equal(t, []string{".", "./pkg", "./pkg/a", "./pkg/empty"}, dirs)
+}
+
 func setupTempDir(t *testing.T) (string, func()) {
 	tmpdir, err := ioutil.TempDir("", "test-expand-paths")
 	require.NoError(t, err)
@@ -129,6 +142,42 @@ func TestPathFilter(t *testing.T) {
 	}
 }
 
+func TestPathFilterGlobs(t *testing.T) {
+	pathFilter := newPathFilter([]string{"**/testdata/**", "internal/gen/*", "*_gen.go"})
+
+	var testcases = []struct {
+		path     string
+		expected bool
+	}{
+		{path: "testdata", expected: true},
+		{path: "pkg/testdata/foo", expected: true},
+		{path: "internal/gen/api", expected: true},
+		{path: "internal/gen/api.go", expected: true},
+		{path: "internal/gen", expected: false},
+		{path: "pkg/internal/gen/api", expected: false},
+		{path: "pkg/models_gen.go", expected: true},
+		{path: "pkg/models.go", expected: false},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, pathFilter(testcase.path), testcase.path)
+	}
+}
+
+func TestFilterSkippedFiles(t *testing.T) {
+	issues := make(chan *Issue, 3)
+	issues <- &Issue{Linter: "vet", Path: "pkg/models_gen.go", Line: 1, Message: "bad"}
+	issues <- &Issue{Linter: "vet", Path: "pkg/models.go", Line: 1, Message: "bad"}
+	issues <- &Issue{Linter: "vet", Path: "pkg/bindata.go", Line: 1, Message: "bad"}
+	close(issues)
+
+	actual := []string{}
+	for issue := range filterSkippedFiles([]string{"*_gen.go", "bindata.go"}, nil, issues) {
+		actual = append(actual, issue.Path)
+	}
+	assert.Equal(t, []string{"pkg/models.go"}, actual)
+}
+
 func TestLoadConfigWithDeadline(t *testing.T) {
 	originalConfig := *config
 	defer func() { config = &originalConfig }()
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// goModule is a Go module, as declared by its go.mod file.
+type goModule struct {
+	// Module path.
+	Path string
+	// Absolute directory containing go.mod.
+	Dir string
+	// Import paths replaced with local directories, longest first.
+	Replaces []moduleReplace
+}
+
+// moduleReplace is a replace directive whose replacement is a local
+// directory.
+type moduleReplace struct {
+	Path string
+	Dir  string
+}
+
+// moduleReplacesByLength sorts replace directives longest path first, so that
+// the most specific replacement is found first.
+type moduleReplacesByLength []moduleReplace
+
+func (m moduleReplacesByLength) Len() int           { return len(m) }
+func (m moduleReplacesByLength) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m moduleReplacesByLength) Less(i, j int) bool { return len(m[i].Path) > len(m[j].Path) }
+
+// modulesEnabled returns true unless module mode has been disabled with
+// GO111MODULE=off.
+func modulesEnabled() bool {
+	return os.Getenv("GO111MODULE") != "off"
+}
+
+// isModuleRoot returns true if dir contains a go.mod file.
+func isModuleRoot(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil && !info.IsDir()
+}
+
+// findModule returns the module containing dir, or nil if dir is not in a
+// module or module mode is disabled.
+func findModule(dir string) (*goModule, error) {
+	if !modulesEnabled() {
+		return nil, nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if isModuleRoot(dir) {
+			return loadGoMod(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// loadGoMod parses the go.mod file in dir.
+func loadGoMod(dir string) (*goModule, error) {
+	path := filepath.Join(dir, "go.mod")
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() // nolint: errcheck
+
+	mod := &goModule{Dir: dir}
+	block := ""
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := goModFields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+			continue
+		case block != "":
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: invalid module directive", path, line)
+			}
+			mod.Path = fields[1]
+		case "replace":
+			replace, ok := parseReplace(dir, fields[1:])
+			if ok {
+				mod.Replaces = append(mod.Replaces, replace)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if mod.Path == "" {
+		return nil, fmt.Errorf("%s: missing module directive", path)
+	}
+	sort.Stable(moduleReplacesByLength(mod.Replaces))
+	return mod, nil
+}
+
+// goModFields splits a go.mod line into fields, removing comments and quotes.
+func goModFields(line string) []string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, "\"`")
+	}
+	return fields
+}
+
+// parseReplace parses the arguments of a replace directive in the go.mod in
+// dir. Replacements by other module versions are ignored, as only local
+// directories can be linted.
+func parseReplace(dir string, args []string) (moduleReplace, bool) {
+	arrow := -1
+	for i, arg := range args {
+		if arg == "=>" {
+			arrow = i
+		}
+	}
+	// Local replacements have no version: "path [version] => dir".
+	if arrow < 1 || arrow != len(args)-2 {
+		return moduleReplace{}, false
+	}
+	target := args[arrow+1]
+	if !filepath.IsAbs(target) && !strings.HasPrefix(target, "./") && !strings.HasPrefix(target, "../") {
+		return moduleReplace{}, false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, filepath.FromSlash(target))
+	}
+	return moduleReplace{Path: args[0], Dir: filepath.Clean(target)}, true
+}
+
+// resolve returns the local directory of the package with importPath, if it
+// is part of the module or replaced with a local directory.
+func (m *goModule) resolve(importPath string) (string, bool) {
+	if rel, ok := trimImportPath(importPath, m.Path); ok {
+		return filepath.Join(m.Dir, filepath.FromSlash(rel)), true
+	}
+	for _, replace := range m.Replaces {
+		if rel, ok := trimImportPath(importPath, replace.Path); ok {
+			return filepath.Join(replace.Dir, filepath.FromSlash(rel)), true
+		}
+	}
+	return "", false
+}
+
+// importPath returns the import path of the package in dir, which must be
+// absolute and within the module.
+func (m *goModule) importPath(dir string) string {
+	rel, err := filepath.Rel(m.Dir, dir)
+	if err != nil || rel == "." {
+		return m.Path
+	}
+	return m.Path + "/" + filepath.ToSlash(rel)
+}
+
+// replacedImportPath returns the import path of the package in dir, which
+// must be absolute, if dir is within a directory that replaces a module.
+func (m *goModule) replacedImportPath(dir string) (string, bool) {
+	for _, replace := range m.Replaces {
+		rel, err := filepath.Rel(replace.Dir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rel == "." {
+			return replace.Path, true
+		}
+		return replace.Path + "/" + filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+// trimImportPath returns importPath relative to prefix, if it has it.
+func trimImportPath(importPath, prefix string) (string, bool) {
+	if importPath == prefix {
+		return ".", true
+	}
+	if strings.HasPrefix(importPath, prefix+"/") {
+		return importPath[len(prefix)+1:], true
+	}
+	return "", false
+}
+
+// resolveImportPath returns the directory of a package given by its import
+// path, if it is not already a directory and can be resolved in the module of
+// the working directory. Otherwise path is returned unchanged.
+func resolveImportPath(path string) string {
+	if filepath.IsAbs(path) || strings.HasPrefix(path, ".") {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	mod, err := findModule(".")
+	if err != nil {
+		warning("%s", err)
+		return path
+	}
+	if mod == nil {
+		return path
+	}
+	dir, ok := mod.resolve(filepath.ToSlash(path))
+	if !ok {
+		return path
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, dir); err == nil {
+			return rel
+		}
+	}
+	return dir
+}
+
+// modulePackageName returns the name that linters running in module mode
+// should be given for the package in path. Paths within the module of the
+// working directory are left relative, while packages in other modules are
+// named by their import path, preferring the path they are replaced for.
+func modulePackageName(path string) (string, bool, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false, err
+	}
+	mod, err := findModule(abs)
+	if err != nil || mod == nil {
+		return "", false, err
+	}
+	main, err := findModule(".")
+	if err != nil {
+		return "", false, err
+	}
+	if main != nil && main.Dir == mod.Dir {
+		if !filepath.IsAbs(path) {
+			return path, true, nil
+		}
+		return mod.importPath(abs), true, nil
+	}
+	if main != nil {
+		if pkg, ok := main.replacedImportPath(abs); ok {
+			return pkg, true, nil
+		}
+	}
+	return mod.importPath(abs), true, nil
+}
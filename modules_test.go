@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mkGoMod(t *testing.T, dir string, content string) {
+	err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0644)
+	require.NoError(t, err)
+}
+
+func enableModules(t *testing.T) func() {
+	old := os.Getenv("GO111MODULE")
+	require.NoError(t, os.Setenv("GO111MODULE", "on"))
+	return func() { require.NoError(t, os.Setenv("GO111MODULE", old)) }
+}
+
+func TestLoadGoMod(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkGoMod(t, tmpdir, `module "example.com/app" // the app
+
+require (
+	example.com/dep v1.0.0
+	example.com/remote v1.0.0
+)
+
+replace example.com/dep => ../dep
+replace (
+	example.com/remote v1.0.0 => example.com/fork v1.1.0
+	example.com/dep/sub v1.0.0 => /abs/sub
+)
+`)
+	mod, err := loadGoMod(tmpdir)
+	require.NoError(t, err)
+	expected := &goModule{
+		Path: "example.com/app",
+		Dir:  tmpdir,
+		Replaces: []moduleReplace{
+			{Path: "example.com/dep/sub", Dir: "/abs/sub"},
+			{Path: "example.com/dep", Dir: filepath.Join(filepath.Dir(tmpdir), "dep")},
+		},
+	}
+	assert.Equal(t, expected, mod)
+}
+
+func TestResolvePathsInModule(t *testing.T) {
+	defer enableModules(t)()
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkGoMod(t, tmpdir, "module example.com/app\n\nreplace example.com/dep => ./third_party/dep\n")
+	mkDir(t, tmpdir, "pkg", "foo")
+	mkDir(t, tmpdir, "nested", "bar")
+	mkGoMod(t, filepath.Join(tmpdir, "nested"), "module example.com/nested\n")
+	mkDir(t, tmpdir, "third_party", "dep", "baz")
+	mkGoMod(t, filepath.Join(tmpdir, "third_party", "dep"), "module example.com/dep\n")
+
+	paths := resolvePaths([]string{"./...", "example.com/dep/..."}, nil)
+	expected := []string{"./pkg/foo", "./third_party/dep/baz"}
+	assert.Equal(t, expected, paths)
+
+	paths = resolvePaths([]string{"example.com/app/pkg"}, nil)
+	assert.Equal(t, []string{"./pkg"}, paths)
+}
+
+func TestPathsToPackagePathsInModule(t *testing.T) {
+	defer enableModules(t)()
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkGoMod(t, tmpdir, "module example.com/app\n\nreplace example.com/dep => ./third_party/dep\n")
+	mkDir(t, tmpdir, "pkg")
+	mkDir(t, tmpdir, "third_party", "dep", "baz")
+	mkGoMod(t, filepath.Join(tmpdir, "third_party", "dep"), "module example.com/dep\n")
+	mkDir(t, tmpdir, "nested")
+	mkGoMod(t, filepath.Join(tmpdir, "nested"), "module example.com/nested\n")
+
+	packagePaths, err := pathsToPackagePaths([]string{
+		"./pkg",
+		filepath.Join(tmpdir, "pkg"),
+		"./third_party/dep/baz",
+		"./nested",
+	})
+	require.NoError(t, err)
+	expected := []string{
+		"./pkg",
+		"example.com/app/pkg",
+		"example.com/dep/baz",
+		"example.com/nested",
+	}
+	assert.Equal(t, expected, packagePaths)
+}
+
+func TestPackageNameFromPathOutsideGoPath(t *testing.T) {
+	defer fakeGoPath(t, "/fake/root")()
+	if _, err := os.Stat("/elsewhere"); err == nil {
+		t.Skip("/elsewhere exists")
+	}
+	_, err := packageNameFromPath("/elsewhere/foo")
+	assert.EqualError(t, err, "/elsewhere/foo not in GOPATH or a Go module")
+}
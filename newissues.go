@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// changedFile records the lines of a file that were added or modified.
+type changedFile struct {
+	// The whole file is new, eg. untracked by git.
+	all   bool
+	lines map[int]bool
+}
+
+// changedLines records the added and modified lines of every file in a diff,
+// keyed by cleaned file path.
+type changedLines map[string]*changedFile
+
+func (c changedLines) file(path string) *changedFile {
+	path = filepath.Clean(filepath.FromSlash(path))
+	file, ok := c[path]
+	if !ok {
+		file = &changedFile{lines: map[int]bool{}}
+		c[path] = file
+	}
+	return file
+}
+
+// touched returns true if path was changed at all.
+func (c changedLines) touched(path string) bool {
+	_, ok := c[filepath.Clean(path)]
+	return ok
+}
+
+// changed returns true if line in path was added or modified.
+func (c changedLines) changed(path string, line int) bool {
+	file, ok := c[filepath.Clean(path)]
+	if !ok {
+		return false
+	}
+	return file.all || file.lines[line]
+}
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseUnifiedDiff extracts the added and modified lines from a unified diff.
+func parseUnifiedDiff(r io.Reader) (changedLines, error) {
+	out := changedLines{}
+	var (
+		file      *changedFile
+		line      int
+		remaining int
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if remaining > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if file != nil {
+					file.lines[line] = true
+				}
+				line++
+				remaining--
+			case strings.HasPrefix(text, " "), text == "":
+				line++
+				remaining--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			path := strings.TrimPrefix(text, "+++ ")
+			if tab := strings.Index(path, "\t"); tab != -1 {
+				path = path[:tab]
+			}
+			if path == "/dev/null" {
+				file = nil
+				continue
+			}
+			file = out.file(strings.TrimPrefix(path, "b/"))
+
+		case strings.HasPrefix(text, "@@ "):
+			match := hunkHeaderRegex.FindStringSubmatch(text)
+			if match == nil {
+				return nil, fmt.Errorf("invalid hunk header %q", text)
+			}
+			line, _ = strconv.Atoi(match[1])
+			remaining = 1
+			if match[2] != "" {
+				remaining, _ = strconv.Atoi(match[2])
+			}
+		}
+	}
+	return out, scanner.Err()
+}
+
+// loadChangedLinesFromRev returns the lines of the working tree that were
+// added or modified since rev. Untracked files are considered entirely new.
+func loadChangedLinesFromRev(rev string) (changedLines, error) {
+	cmd := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "--relative", "-U0", // nolint: gas
+		"--src-prefix=a/", "--dst-prefix=b/", rev, "--")
+	cmd.Stderr = os.Stderr
+	diff, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s failed: %s", rev, err)
+	}
+	out, err := parseUnifiedDiff(bytes.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard") // nolint: gas
+	cmd.Stderr = os.Stderr
+	untracked, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %s", err)
+	}
+	for _, path := range strings.Split(string(untracked), "\n") {
+		if path != "" {
+			out.file(path).all = true
+		}
+	}
+	return out, nil
+}
+
+func loadChangedLinesFromPatch(path string) (changedLines, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() // nolint: errcheck
+	return parseUnifiedDiff(r)
+}
+
+// isWholeFileIssue returns true if the issue was reported by a linter that
+// does not report line numbers, such as gofmt.
+func isWholeFileIssue(linters map[string]*Linter, issue *Issue) bool {
+	for _, name := range strings.Split(issue.Linter, ", ") {
+		linter, ok := linters[name]
+		if !ok || reportsLines(linter) {
+			return false
+		}
+	}
+	return true
+}
+
+// reportsLines returns true if the linter's issues have line numbers, either
+// captured by its pattern or, for the "json" format, read from the line
+// field unless that is mapped to an empty path.
+func reportsLines(linter *Linter) bool {
+	if linter.Format == "json" {
+		path, ok := linter.Fields["line"]
+		return !ok || path != ""
+	}
+	for _, group := range linter.regex.SubexpNames() {
+		if group == "line" {
+			return true
+		}
+	}
+	return false
+}
+
+func filterIssuesViaChangedLines(changes changedLines, linters map[string]*Linter, issues chan *Issue) chan *Issue {
+	out := make(chan *Issue, 1000000)
+	go func() {
+		for issue := range issues {
+			switch {
+			case changes.changed(issue.Path, issue.Line):
+				out <- issue
+			case config.NewFileIssues && changes.touched(issue.Path) && isWholeFileIssue(linters, issue):
+				out <- issue
+			default:
+				debug("ignoring issue on unchanged line: %s", issue)
+			}
+		}
+		close(out)
+	}()
+	return out
+}
+
+func maybeFilterNewIssues(linters map[string]*Linter, issues chan *Issue) chan *Issue {
+	if config.changedLines == nil {
+		return issues
+	}
+	return filterIssuesViaChangedLines(config.changedLines, linters, issues)
+}
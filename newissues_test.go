@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDiff = `diff --git a/foo/a.go b/foo/a.go
+index 1111111..2222222 100644
+--- a/foo/a.go
++++ b/foo/a.go
+@@ -3,0 +4,2 @@ func a() {
++	x := 1
++	y := 2
+@@ -10 +12 @@ func b() {
+-	return
++	return nil
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -1,3 +1,4 @@
+ package b
++
+ func b() {
+ }
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	changes, err := parseUnifiedDiff(strings.NewReader(testDiff))
+	require.NoError(t, err)
+
+	assert.True(t, changes.changed("foo/a.go", 4))
+	assert.True(t, changes.changed("foo/a.go", 5))
+	assert.False(t, changes.changed("foo/a.go", 6))
+	assert.True(t, changes.changed("foo/a.go", 12))
+	assert.False(t, changes.changed("gone.go", 1))
+	assert.False(t, changes.changed("b.go", 1))
+	assert.True(t, changes.changed("b.go", 2))
+	assert.False(t, changes.changed("b.go", 3))
+	assert.True(t, changes.touched("./b.go"))
+	assert.False(t, changes.touched("c.go"))
+}
+
+func TestFilterIssuesViaChangedLines(t *testing.T) {
+	originalConfig := *config
+	defer func() { config = &originalConfig }()
+
+	changes, err := parseUnifiedDiff(strings.NewReader(testDiff))
+	require.NoError(t, err)
+	linters := map[string]*Linter{
+		"golint":   getLinterByName("golint", LinterConfig{}),
+		"gofmt":    getLinterByName("gofmt", LinterConfig{}),
+		"jsonlint": {Name: "jsonlint", LinterConfig: LinterConfig{Format: "json"}},
+		"jsonfile": {Name: "jsonfile", LinterConfig: LinterConfig{Format: "json", Fields: map[string]string{"line": ""}}},
+	}
+	input := []*Issue{
+		{Linter: "golint", Path: "foo/a.go", Line: 4},
+		{Linter: "golint", Path: "foo/a.go", Line: 7},
+		{Linter: "gofmt", Path: "b.go", Line: 1},
+		{Linter: "gofmt", Path: "c.go", Line: 1},
+		{Linter: "jsonlint", Path: "b.go", Line: 3},
+		{Linter: "jsonfile", Path: "b.go", Line: 1},
+	}
+
+	filter := func() []*Issue {
+		issues := make(chan *Issue, len(input))
+		for _, issue := range input {
+			issues <- issue
+		}
+		close(issues)
+		out := []*Issue{}
+		for issue := range filterIssuesViaChangedLines(changes, linters, issues) {
+			out = append(out, issue)
+		}
+		return out
+	}
+
+	assert.Equal(t, input[:1], filter())
+	config.NewFileIssues = true
+	assert.Equal(t, []*Issue{input[0], input[2], input[5]}, filter())
+}
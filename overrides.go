@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigOverride applies settings to the packages whose directories match any
+// of Paths.
+type ConfigOverride struct {
+	// Globs matched against package directories, relative to the working
+	// directory and with forward slashes. "**" matches any number of
+	// directories.
+	Paths []string
+	// A partial Config, containing only settings in overridableConfig. It is
+	// applied over the rest of the configuration as if it were a file that
+	// extends it.
+	Config json.RawMessage
+}
+
+// Settings that may be set by a ConfigOverride. Other settings apply to the
+// run as a whole.
+var overridableConfig = map[string]bool{
+	"Linters":         true,
+	"Enable":          true,
+	"Disable":         true,
+	"Fast":            true,
+	"Test":            true,
+	"Deadline":        true,
+	"Cyclo":           true,
+	"LineLength":      true,
+	"MinConfidence":   true,
+	"MinOccurrences":  true,
+	"MinConstLength":  true,
+	"DuplThreshold":   true,
+	"MessageOverride": true,
+	"Severity":        true,
+}
+
+// pathGroup is a set of paths linted with the same configuration.
+type pathGroup struct {
+	// Indexes of the overrides applied to the group.
+	overrides []int
+	config    *Config
+	linters   map[string]*Linter
+	paths     []string
+}
+
+func (g *pathGroup) String() string {
+	if len(g.overrides) == 0 {
+		return "default"
+	}
+	names := []string{}
+	for _, i := range g.overrides {
+		names = append(names, fmt.Sprintf("override %d", i+1))
+	}
+	return strings.Join(names, ", ")
+}
+
+// applyOverrides returns a copy of base with the given overrides applied.
+func applyOverrides(base *Config, overrides []int) (*Config, error) {
+	out := base.clone()
+	loader := newConfigLoader(out)
+	for _, i := range overrides {
+		if err := loader.apply(base.Overrides[i].Config, fmt.Sprintf("override %d", i+1)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// validateOverrides checks that each override contains only overridable
+// settings and enables only known linters.
+func validateOverrides(config *Config) error {
+	for i, override := range config.Overrides {
+		if len(override.Paths) == 0 {
+			return fmt.Errorf("override %d: Paths is required", i+1)
+		}
+		for _, glob := range override.Paths {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("override %d: invalid path glob %q: %s", i+1, glob, err)
+			}
+		}
+		keys := map[string]json.RawMessage{}
+		if err := json.Unmarshal(override.Config, &keys); err != nil {
+			return fmt.Errorf("override %d: %s", i+1, err)
+		}
+		for _, name := range configFieldNames(keys) {
+			if !overridableConfig[name] {
+				return fmt.Errorf("override %d: %s can not be overridden per path", i+1, name)
+			}
+		}
+		overridden, err := applyOverrides(config, []int{i})
+		if err != nil {
+			return err
+		}
+		if err := validateLinters(lintersFromConfig(overridden), overridden); err != nil {
+			return fmt.Errorf("override %d: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+// groupPaths splits paths into groups by the overrides that apply to them.
+// Paths matching no overrides are linted with linters and the global config.
+func groupPaths(linters map[string]*Linter, paths []string) ([]*pathGroup, error) {
+	groups := map[string]*pathGroup{}
+	for _, p := range paths {
+		name := filepath.ToSlash(filepath.Clean(p))
+		overrides := []int{}
+		for i, override := range config.Overrides {
+			for _, glob := range override.Paths {
+				if matchPathGlob(glob, name) {
+					overrides = append(overrides, i)
+					break
+				}
+			}
+		}
+		key := fmt.Sprintf("%v", overrides)
+		group, ok := groups[key]
+		if !ok {
+			group = &pathGroup{overrides: overrides, config: config, linters: linters}
+			if len(overrides) > 0 {
+				overridden, err := applyOverrides(config, overrides)
+				if err != nil {
+					return nil, err
+				}
+				group.config = overridden
+				group.linters = lintersFromConfig(overridden)
+			}
+			groups[key] = group
+		}
+		group.paths = append(group.paths, p)
+	}
+
+	out := make([]*pathGroup, 0, len(groups))
+	for _, group := range groups {
+		out = append(out, group)
+	}
+	sort.Sort(pathGroups(out))
+	return out, nil
+}
+
+type pathGroups []*pathGroup
+
+func (p pathGroups) Len() int           { return len(p) }
+func (p pathGroups) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p pathGroups) Less(i, j int) bool { return p[i].String() < p[j].String() }
+
+// dedupeIssues removes issues identical to one already seen, such as those
+// reported by the same linter run over several groups of paths.
+func dedupeIssues(issues chan *Issue) chan *Issue {
+	out := make(chan *Issue, 1000000)
+	go func() {
+		seen := map[string]bool{}
+		for issue := range issues {
+			key := fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%s", issue.Linter, issue.Path, issue.Line, issue.Col, issue.Message)
+			if seen[key] {
+				debug("ignoring duplicate issue %s", issue)
+				continue
+			}
+			seen[key] = true
+			out <- issue
+		}
+		close(out)
+	}()
+	return out
+}
+
+func maybeDedupeIssues(issues chan *Issue) chan *Issue {
+	if len(config.Overrides) == 0 {
+		return issues
+	}
+	return dedupeIssues(issues)
+}
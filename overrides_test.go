@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOverrides(t *testing.T) {
+	var testcases = []struct {
+		override *ConfigOverride
+		expected string
+	}{
+		{
+			override: &ConfigOverride{Config: json.RawMessage(`{"Cyclo": 20}`)},
+			expected: "override 1: Paths is required",
+		},
+		{
+			override: &ConfigOverride{Paths: []string{"["}, Config: json.RawMessage(`{}`)},
+			expected: `override 1: invalid path glob "[": syntax error in pattern`,
+		},
+		{
+			override: &ConfigOverride{Paths: []string{"gen/**"}, Config: json.RawMessage(`{"Format": "{{.Path}}"}`)},
+			expected: "override 1: Format can not be overridden per path",
+		},
+		{
+			override: &ConfigOverride{Paths: []string{"gen/**"}, Config: json.RawMessage(`{"Enable": ["nonexistent"]}`)},
+			expected: "override 1: unknown linters: nonexistent",
+		},
+	}
+	for _, testcase := range testcases {
+		err := validateOverrides(&Config{Overrides: []*ConfigOverride{testcase.override}})
+		if assert.Error(t, err) {
+			assert.Equal(t, testcase.expected, err.Error())
+		}
+	}
+
+	valid := &Config{Overrides: []*ConfigOverride{
+		{Paths: []string{"gen/**"}, Config: json.RawMessage(`{"Enable": ["golint"], "Cyclo": 30}`)},
+	}}
+	assert.NoError(t, validateOverrides(valid))
+}
+
+func TestRunLintersWithOverrides(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.Cyclo = 10
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	config.Overrides = []*ConfigOverride{
+		{Paths: []string{"legacy/**"}, Config: json.RawMessage(`{"Cyclo": 30}`)},
+		{Paths: []string{"legacy/gen"}, Config: json.RawMessage(`{"Disable": ["cyclo"]}`)},
+	}
+
+	mkDir(t, tmpdir, "pkg")
+	mkDir(t, tmpdir, "legacy")
+	mkDir(t, tmpdir, "legacy", "gen")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$2/file.go:1: over $1\"\n"), 0755)
+	require.NoError(t, err)
+	linterConfig, err := parseLinterConfigSpec("cyclo", script+" {mincyclo}:PATH:LINE:MESSAGE")
+	require.NoError(t, err)
+	linterConfig.PartitionStrategy = partitionPathsAsDirectories
+	config.Linters["cyclo"] = StringOrLinterConfig(linterConfig)
+	config.Enable = []string{"cyclo"}
+	linters := lintersFromConfig(config)
+
+	issues, errch, _ := runLinters(linters, []string{"./pkg", "./legacy", "./legacy/gen"}, 2, nil, nil, nil)
+	actual := []string{}
+	for issue := range issues {
+		actual = append(actual, issue.Path+": "+issue.Message)
+	}
+	for err := range errch {
+		require.NoError(t, err)
+	}
+	sort.Strings(actual)
+	assert.Equal(t, []string{
+		filepath.Join("legacy", "file.go") + ": over 30",
+		filepath.Join("pkg", "file.go") + ": over 10",
+	}, actual)
+}
+
+func TestRunLintersWithSeverityAndMessageOverrides(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+	defer func(original *Config) { config = original }(config)
+	config = config.clone()
+	config.Cache = false
+	config.formatTemplate, _ = newIssueFormatTemplate(DefaultIssueFormat)
+	config.Severity = map[string]string{"fake": "error"}
+	config.MessageOverride = map[string]string{}
+	config.Overrides = []*ConfigOverride{
+		{Paths: []string{"legacy"}, Config: json.RawMessage(`{"Severity": {"fake": "warning"}, "MessageOverride": {"fake": "legacy: {message}"}}`)},
+	}
+
+	mkDir(t, tmpdir, "pkg")
+	mkDir(t, tmpdir, "legacy")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$1/file.go:1: found it\"\n"), 0755)
+	require.NoError(t, err)
+	linterConfig, err := parseLinterConfigSpec("fake", script+":PATH:LINE:MESSAGE")
+	require.NoError(t, err)
+	linterConfig.PartitionStrategy = partitionPathsAsDirectories
+	config.Linters["fake"] = StringOrLinterConfig(linterConfig)
+	config.Enable = []string{"fake"}
+	linters := lintersFromConfig(config)
+
+	issues, errch, _ := runLinters(linters, []string{"./pkg", "./legacy"}, 2, nil, nil, nil)
+	actual := []string{}
+	for issue := range issues {
+		actual = append(actual, fmt.Sprintf("%s: %s: %s", issue.Path, issue.Severity, issue.Message))
+	}
+	for err := range errch {
+		require.NoError(t, err)
+	}
+	sort.Strings(actual)
+	assert.Equal(t, []string{
+		filepath.Join("legacy", "file.go") + ": warning: legacy: found it",
+		filepath.Join("pkg", "file.go") + ": error: found it",
+	}, actual)
+}
+
+func TestDedupeIssues(t *testing.T) {
+	issues := make(chan *Issue, 3)
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 1, Message: "bad"}
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 1, Message: "bad"}
+	issues <- &Issue{Linter: "vet", Path: "a.go", Line: 2, Message: "bad"}
+	close(issues)
+
+	actual := []*Issue{}
+	for issue := range dedupeIssues(issues) {
+		actual = append(actual, issue)
+	}
+	assert.Len(t, actual, 2)
+}
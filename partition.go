@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"reflect"
+	"strings"
 )
 
 // MaxCommandBytes is the maximum number of bytes used when executing a command
@@ -11,29 +13,46 @@ const MaxCommandBytes = 32000
 
 type partitionStrategy func([]string, []string) ([][]string, error)
 
+// Partition strategies by the names used to configure them.
+var partitionStrategies = map[string]partitionStrategy{
+	"directories":      partitionPathsAsDirectories,
+	"files":            partitionPathsAsFiles,
+	"packages":         partitionPathsAsPackages,
+	"files-by-package": partitionPathsAsFilesGroupedByPackage,
+	"single-directory": partitionPathsByDirectory,
+}
+
 func (ps *partitionStrategy) UnmarshalJSON(raw []byte) error {
 	var strategyName string
 	if err := json.Unmarshal(raw, &strategyName); err != nil {
 		return err
 	}
 
-	switch strategyName {
-	case "directories":
-		*ps = partitionPathsAsDirectories
-	case "files":
-		*ps = partitionPathsAsFiles
-	case "packages":
-		*ps = partitionPathsAsPackages
-	case "files-by-package":
-		*ps = partitionPathsAsFilesGroupedByPackage
-	case "single-directory":
-		*ps = partitionPathsByDirectory
-	default:
+	strategy, ok := partitionStrategies[strategyName]
+	if !ok {
 		return fmt.Errorf("unknown parition strategy %s", strategyName)
 	}
+	*ps = strategy
 	return nil
 }
 
+func (ps partitionStrategy) MarshalJSON() ([]byte, error) {
+	if ps == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ps.String())
+}
+
+// String returns the name of the strategy.
+func (ps partitionStrategy) String() string {
+	for name, strategy := range partitionStrategies {
+		if reflect.ValueOf(strategy).Pointer() == reflect.ValueOf(ps).Pointer() {
+			return name
+		}
+	}
+	return ""
+}
+
 func pathsToFileGlobs(paths []string) ([]string, error) {
 	filePaths := []string{}
 	for _, dir := range paths {
@@ -140,18 +159,25 @@ func pathsToPackagePaths(paths []string) ([]string, error) {
 	return packages, nil
 }
 
+// packageNameFromPath returns the name linters should be given for the
+// package in path: its path relative to the working directory if it is in
+// the same module, otherwise its import path in module or GOPATH mode.
 func packageNameFromPath(path string) (string, error) {
+	pkg, ok, err := modulePackageName(path)
+	if err != nil || ok {
+		return pkg, err
+	}
 	if !filepath.IsAbs(path) {
 		return path, nil
 	}
 	for _, gopath := range getGoPathList() {
 		rel, err := filepath.Rel(filepath.Join(gopath, "src"), path)
-		if err != nil {
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
 			continue
 		}
-		return rel, nil
+		return filepath.ToSlash(rel), nil
 	}
-	return "", fmt.Errorf("%s not in GOPATH", path)
+	return "", fmt.Errorf("%s not in GOPATH or a Go module", path)
 }
 
 func partitionPathsByDirectory(cmdArgs []string, paths []string) ([][]string, error) {
@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os/exec"
+)
+
+// startProcessGroup does nothing, as Windows has no process groups that can
+// be signalled.
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup kills the process, as Windows processes can not be asked
+// to terminate.
+func signalProcessGroup(cmd *exec.Cmd, kill bool) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string      `json:"version"`
+	Schema  string      `json:"$schema"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevel maps a gometalinter severity to a SARIF result level.
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// newSARIFLog builds a SARIF log with one run per linter. Aggregated issues
+// are attributed to the first linter that reported them.
+func newSARIFLog(linters map[string]*Linter, issues chan *Issue) *sarifLog {
+	out := &sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []*sarifRun{},
+	}
+	runs := map[string]*sarifRun{}
+	getRun := func(name string) *sarifRun {
+		if run, ok := runs[name]; ok {
+			return run
+		}
+		run := &sarifRun{
+			Tool:    sarifTool{Driver: sarifDriver{Name: name}},
+			Results: []*sarifResult{},
+		}
+		if linter, ok := linters[name]; ok && linter.InstallFrom != "" {
+			run.Tool.Driver.InformationURI = "https://" + linter.InstallFrom
+		}
+		runs[name] = run
+		return run
+	}
+
+	names := make([]string, 0, len(linters))
+	for name := range linters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.Runs = append(out.Runs, getRun(name))
+	}
+
+	for issue := range issues {
+		if config.Errors && issue.Severity != Error {
+			continue
+		}
+		name := strings.SplitN(issue.Linter, ", ", 2)[0]
+		run, ok := runs[name]
+		if !ok {
+			run = getRun(name)
+			out.Runs = append(out.Runs, run)
+		}
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(issue.Path)},
+		}
+		// Issues about a whole file have no line, and SARIF requires startLine
+		// to be at least 1, so they are reported without a region.
+		if issue.Line > 0 {
+			location.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Col}
+		}
+		run.Results = append(run.Results, &sarifResult{
+			RuleID:    issue.Rule,
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+	return out
+}
+
+func outputToSARIF(linters map[string]*Linter, issues chan *Issue) int {
+	out := newSARIFLog(linters, issues)
+	status := 0
+	for _, run := range out.Runs {
+		if len(run.Results) > 0 {
+			status = 1
+		}
+	}
+	d, err := json.MarshalIndent(out, "", "  ")
+	kingpin.FatalIfError(err, "")
+	fmt.Printf("%s\n", d)
+	return status
+}
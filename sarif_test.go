@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSARIFLog(t *testing.T) {
+	linters := map[string]*Linter{
+		"vet":    getLinterByName("vet", LinterConfig{}),
+		"golint": getLinterByName("golint", LinterConfig{}),
+	}
+	issues := make(chan *Issue, 3)
+	issues <- &Issue{Linter: "vet", Severity: Error, Path: "foo/a.go", Line: 3, Col: 2, Message: "bad"}
+	issues <- &Issue{Linter: "golint, vet", Severity: Warning, Path: "b.go", Line: 1, Message: "meh"}
+	issues <- &Issue{Linter: "custom", Severity: "info", Path: "c.go", Line: 7, Message: "fyi"}
+	close(issues)
+
+	log := newSARIFLog(linters, issues)
+	assert.Equal(t, sarifVersion, log.Version)
+	require.Len(t, log.Runs, 3)
+
+	golint := log.Runs[0]
+	assert.Equal(t, "golint", golint.Tool.Driver.Name)
+	assert.Equal(t, "https://github.com/golang/lint/golint", golint.Tool.Driver.InformationURI)
+	require.Len(t, golint.Results, 1)
+	assert.Equal(t, "warning", golint.Results[0].Level)
+
+	vet := log.Runs[1]
+	assert.Equal(t, "vet", vet.Tool.Driver.Name)
+	require.Len(t, vet.Results, 1)
+	expected := &sarifResult{
+		Level:   "error",
+		Message: sarifMessage{Text: "bad"},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "foo/a.go"},
+				Region:           &sarifRegion{StartLine: 3, StartColumn: 2},
+			},
+		}},
+	}
+	assert.Equal(t, expected, vet.Results[0])
+
+	custom := log.Runs[2]
+	assert.Equal(t, "custom", custom.Tool.Driver.Name)
+	assert.Equal(t, "", custom.Tool.Driver.InformationURI)
+	require.Len(t, custom.Results, 1)
+	assert.Equal(t, "note", custom.Results[0].Level)
+}
+
+func TestNewSARIFLogWholeFileIssue(t *testing.T) {
+	issues := make(chan *Issue, 1)
+	issues <- &Issue{Linter: "vet", Severity: Error, Path: "a.go", Message: "bad file"}
+	close(issues)
+
+	log := newSARIFLog(map[string]*Linter{}, issues)
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+	location := log.Runs[0].Results[0].Locations[0].PhysicalLocation
+	assert.Nil(t, location.Region)
+
+	data, err := json.Marshal(location)
+	require.NoError(t, err)
+	assert.Equal(t, `{"artifactLocation":{"uri":"a.go"}}`, string(data))
+}
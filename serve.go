@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v3-unstable"
+)
+
+// serveRequest is sent by a client to the daemon, one per connection.
+type serveRequest struct {
+	// Working directory that paths are relative to.
+	Dir string
+	// Paths to lint, in addition to any in Args.
+	Paths []string
+	// If not empty, exactly these linters are enabled.
+	Enable []string
+	// Command-line flags and paths, as accepted by "lint".
+	Args []string
+}
+
+// serveResponse is streamed back to the client, one per line, as issues are
+// found. The connection is closed once linting is complete.
+type serveResponse struct {
+	Issue *Issue `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
+
+// serveFatal is raised in place of exiting when a request fails fatally.
+type serveFatal int
+
+type server struct {
+	lock sync.Mutex
+	base *Config
+}
+
+func newServer(base *Config) *server {
+	return &server{base: base.clone()}
+}
+
+// serve lints paths on behalf of clients connecting to socket, until
+// interrupted. The configuration is validated once, here, and each request
+// applies its flags on top of it.
+func serve(socket string) error {
+	if errs := validateConfig(config); len(errs) > 0 {
+		messages := []string{}
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return fmt.Errorf("invalid configuration:\n%s", strings.Join(messages, "\n"))
+	}
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			_ = conn.Close()
+			return fmt.Errorf("%s is already in use", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	stopped := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stopped)
+		_ = listener.Close()
+	}()
+
+	// Errors that would otherwise terminate gometalinter are reported to the
+	// client instead.
+	kingpin.CommandLine.Terminate(func(status int) { panic(serveFatal(status)) })
+
+	s := newServer(config)
+	debug("serve: listening on %s", socket)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-stopped:
+				runningLinters.interrupt()
+				return nil
+			default:
+				return err
+			}
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer conn.Close() // nolint: errcheck
+	req := &serveRequest{}
+	if err := json.NewDecoder(conn).Decode(req); err != nil {
+		warning("serve: invalid request: %s", err)
+		return
+	}
+	enc := json.NewEncoder(conn)
+	if err := s.lint(req, enc); err != nil {
+		_ = enc.Encode(&serveResponse{Error: err.Error()})
+	}
+}
+
+// lint handles a single request. Requests are handled one at a time, as they
+// share the global configuration and working directory. The daemon's
+// configuration file is not reloaded, only the request's flags are applied to
+// a copy of it.
+// nolint: gocyclo
+func (s *server) lint(req *serveRequest, enc *json.Encoder) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	defer os.Chdir(cwd) // nolint: errcheck
+
+	original := config
+	config = s.base.clone()
+	defer func() { config = original }()
+
+	stderr := &bytes.Buffer{}
+	kingpin.CommandLine.Writers(os.Stdout, stderr)
+	defer kingpin.CommandLine.Writers(os.Stdout, os.Stderr)
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(serveFatal); ok {
+				err = errors.New(strings.TrimSpace(stderr.String()))
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	if req.Dir != "" {
+		if err := os.Chdir(req.Dir); err != nil {
+			return err
+		}
+	}
+	app := kingpin.New("gometalinter", "")
+	app.Writers(stderr, stderr)
+	setupFlags(app)
+	pathsArg := app.Arg("path", "Directories to lint.").Strings()
+	if _, err := app.Parse(req.Args); err != nil {
+		return err
+	}
+	if len(req.Enable) > 0 {
+		config.Enable = req.Enable
+	}
+
+	include, exclude := processConfig(config)
+	paths := resolvePaths(append(*pathsArg, req.Paths...), config.Skip)
+	linters := lintersFromConfig(config)
+	if err := validateLinters(linters, config); err != nil {
+		return err
+	}
+
+	cancel := make(chan struct{})
+	cancelled := false
+	issues, errch, _ := runLinters(linters, paths, config.Concurrency, exclude, include, cancel)
+	for issue := range issues {
+		if cancelled {
+			continue
+		}
+		if err := enc.Encode(&serveResponse{Issue: issue}); err != nil {
+			debug("serve: client went away: %s", err)
+			close(cancel)
+			cancelled = true
+		}
+	}
+	for err := range errch {
+		if !cancelled {
+			_ = enc.Encode(&serveResponse{Error: err.Error()})
+		}
+	}
+	return nil
+}
+
+// clientArgs returns the arguments to forward to the daemon, which are those
+// given to "client" less the command itself and --socket.
+func clientArgs(args []string) []string {
+	out := []string{}
+	sawCommand := false
+	skipValue := false
+	for _, arg := range args {
+		switch {
+		case skipValue:
+			skipValue = false
+		case arg == "--socket":
+			skipValue = true
+		case strings.HasPrefix(arg, "--socket="):
+		case arg == "client" && !sawCommand:
+			sawCommand = true
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out
+}
+
+// runClient sends args to the daemon listening on socket and writes the issues
+// it returns in the configured output format. Returns the exit status.
+func runClient(socket string, args []string) int {
+	conn, err := net.Dial("unix", socket)
+	kingpin.FatalIfError(err, "failed to connect to gometalinter daemon")
+	defer conn.Close() // nolint: errcheck
+
+	cwd, err := os.Getwd()
+	kingpin.FatalIfError(err, "")
+	err = json.NewEncoder(conn).Encode(&serveRequest{Dir: cwd, Args: args})
+	kingpin.FatalIfError(err, "failed to send request to gometalinter daemon")
+
+	tmpl, err := newIssueFormatTemplate(config.Format)
+	kingpin.FatalIfError(err, "invalid format %q", config.Format)
+
+	issues := make(chan *Issue, 1000000)
+	errch := make(chan error, 1000000)
+	go func() {
+		dec := json.NewDecoder(conn)
+		for {
+			resp := &serveResponse{}
+			if err := dec.Decode(resp); err != nil {
+				if err != io.EOF {
+					errch <- err
+				}
+				break
+			}
+			if resp.Issue != nil {
+				resp.Issue.formatTmpl = tmpl
+				issues <- resp.Issue
+			}
+			if resp.Error != "" {
+				errch <- errors.New(resp.Error)
+			}
+		}
+		close(issues)
+		close(errch)
+	}()
+	return outputIssues(lintersFromConfig(config), &executionLog{}, issues, errch)
+}
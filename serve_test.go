@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientArgs(t *testing.T) {
+	var testcases = []struct {
+		args     []string
+		expected []string
+	}{
+		{
+			args:     []string{"client", "--socket=/tmp/s", "./..."},
+			expected: []string{"./..."},
+		},
+		{
+			args:     []string{"--socket", "/tmp/s", "client", "--fast", "-E", "golint", "."},
+			expected: []string{"--fast", "-E", "golint", "."},
+		},
+		{
+			args:     []string{"client", "./client"},
+			expected: []string{"./client"},
+		},
+	}
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, clientArgs(testcase.args))
+	}
+}
+
+func TestConfigClone(t *testing.T) {
+	original := &Config{
+		Enable:   []string{"golint"},
+		Severity: map[string]string{"golint": "warning"},
+	}
+	clone := original.clone()
+	clone.Enable = append(clone.Enable[:0], "vet")
+	clone.Severity["golint"] = "error"
+
+	assert.Equal(t, []string{"golint"}, original.Enable)
+	assert.Equal(t, "warning", original.Severity["golint"])
+}
+
+func TestServerLint(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "pkg")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$1/file.go:1: found it\"\n"), 0755)
+	require.NoError(t, err)
+
+	responses := serveLintRequest(t, &serveRequest{
+		Dir:    tmpdir,
+		Paths:  []string{"./pkg"},
+		Enable: []string{"fake"},
+		Args:   []string{"--no-config", "--disable-all", "--no-cache", "--linter=fake:" + script + ":PATH:LINE:MESSAGE"},
+	})
+	require.Len(t, responses, 1)
+	require.NotNil(t, responses[0].Issue)
+	assert.Equal(t, "fake", responses[0].Issue.Linter)
+	assert.Equal(t, "found it", responses[0].Issue.Message)
+	assert.Equal(t, 1, responses[0].Issue.Line)
+}
+
+func TestServerLintInvalidLinterOutput(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "pkg")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$1/file.go:one: found it\"\n"), 0755)
+	require.NoError(t, err)
+
+	// The error should be reported to the client, rather than crashing the
+	// daemon.
+	responses := serveLintRequest(t, &serveRequest{
+		Dir:    tmpdir,
+		Paths:  []string{"./pkg"},
+		Enable: []string{"fake"},
+		Args:   []string{"--no-config", "--disable-all", "--no-cache", `--linter=fake:` + script + `:^(?P<path>.*?\.go):(?P<line>\w+): (?P<message>.*)$`},
+	})
+	require.Len(t, responses, 1)
+	assert.Contains(t, responses[0].Error, "line matched invalid integer")
+}
+
+func TestServerLintDoesNotReloadConfig(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "pkg")
+	script := filepath.Join(tmpdir, "fakelint")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$1/file.go:1: found it\"\n"), 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(tmpdir, ".gometalinter.json"), []byte(`{"Exclude": ["found it"]}`), 0644)
+	require.NoError(t, err)
+
+	base := config.clone()
+	base.Exclude = []string{"unrelated"}
+	s := newServer(base)
+	req := &serveRequest{
+		Dir:    tmpdir,
+		Paths:  []string{"./pkg"},
+		Enable: []string{"fake"},
+		Args:   []string{"--disable-all", "--no-cache", "--linter=fake:" + script + ":PATH:LINE:MESSAGE"},
+	}
+	// The configuration file in the request's directory was not loaded by the
+	// daemon, so is ignored, and repeated requests see the same configuration.
+	for i := 0; i < 2; i++ {
+		responses := serveLintRequestTo(t, s, req)
+		require.Len(t, responses, 1)
+		require.NotNil(t, responses[0].Issue)
+		assert.Equal(t, []string{"unrelated"}, s.base.Exclude)
+	}
+}
+
+func serveLintRequest(t *testing.T, req *serveRequest) []*serveResponse {
+	return serveLintRequestTo(t, newServer(config), req)
+}
+
+func serveLintRequestTo(t *testing.T, s *server, req *serveRequest) []*serveResponse {
+	server, client := net.Pipe()
+	go s.handle(server)
+
+	err := json.NewEncoder(client).Encode(req)
+	require.NoError(t, err)
+
+	responses := []*serveResponse{}
+	dec := json.NewDecoder(client)
+	for {
+		response := &serveResponse{}
+		err := dec.Decode(response)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		responses = append(responses, response)
+	}
+	return responses
+}
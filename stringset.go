@@ -16,6 +16,11 @@ func (s *stringSet) add(item string) {
 	s.items[item] = struct{}{}
 }
 
+func (s *stringSet) contains(item string) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
 func (s *stringSet) asSlice() []string {
 	items := []string{}
 	for item := range s.items {
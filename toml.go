@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML decodes a TOML configuration file. Dates and times, which can not
+// be represented as JSON, are rejected.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	document := map[string]interface{}{}
+	if _, err := toml.Decode(string(data), &document); err != nil {
+		return nil, err
+	}
+	if err := checkTOMLValue("", document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// checkTOMLValue returns an error if the value at key, or any value within it,
+// can not be converted to JSON.
+func checkTOMLValue(key string, value interface{}) error {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if key != "" {
+				child = key + "." + k
+			}
+			if err := checkTOMLValue(child, value[k]); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for i, v := range value {
+			if err := checkTOMLValue(fmt.Sprintf("%s[%d]", key, i), v); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, v := range value {
+			if err := checkTOMLValue(fmt.Sprintf("%s[%d]", key, i), v); err != nil {
+				return err
+			}
+		}
+	case time.Time:
+		return fmt.Errorf("toml: unsupported date or time for key %q", key)
+	}
+	return nil
+}
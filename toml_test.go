@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTOML(t *testing.T) {
+	source := `
+# Comment
+Fast = true
+Cyclo = 1_5
+MinConfidence = 0.5
+Format = "{{.Path}}:\t{{.Message}}" # trailing comment
+Skip = [
+  'vendor',
+  "testdata", # comment in array
+]
+
+[Severity]
+golint = "error"
+
+[Linters.custom]
+Command = """
+custom-lint"""
+Pattern = '^(?P<path>.*?):(?P<line>\d+): (?P<message>.*)$'
+Tags = {Fast = false, Names = []}
+
+[[ExcludeRules]]
+Linters = ["errcheck"]
+
+[[ExcludeRules]]
+Message = "ignored"
+`
+	actual, err := parseTOML([]byte(source))
+	require.NoError(t, err)
+	expected := map[string]interface{}{
+		"Fast":          true,
+		"Cyclo":         int64(15),
+		"MinConfidence": 0.5,
+		"Format":        "{{.Path}}:\t{{.Message}}",
+		"Skip":          []interface{}{"vendor", "testdata"},
+		"Severity":      map[string]interface{}{"golint": "error"},
+		"Linters": map[string]interface{}{
+			"custom": map[string]interface{}{
+				"Command": "custom-lint",
+				"Pattern": `^(?P<path>.*?):(?P<line>\d+): (?P<message>.*)$`,
+				"Tags":    map[string]interface{}{"Fast": false, "Names": []interface{}{}},
+			},
+		},
+		"ExcludeRules": []map[string]interface{}{
+			{"Linters": []interface{}{"errcheck"}},
+			{"Message": "ignored"},
+		},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	var testcases = []struct {
+		source   string
+		expected string
+	}{
+		{source: "a = 1\na = 2", expected: `Near line 2 (last key parsed 'a'): Key 'a' has already been defined.`},
+		{source: "a = \"unterminated\n", expected: `Near line 1 (last key parsed 'a'): strings cannot contain newlines`},
+		{source: "a = 1979-05-27T07:32:00Z", expected: `toml: unsupported date or time for key "a"`},
+		{source: "[a]\nb = [1979-05-27T07:32:00Z]", expected: `toml: unsupported date or time for key "a.b[0]"`},
+		{source: "a = nan", expected: `Near line 1 (last key parsed 'a'): expected value but found "nan" instead`},
+	}
+	for _, testcase := range testcases {
+		_, err := parseTOML([]byte(testcase.source))
+		if assert.Error(t, err, testcase.source) {
+			assert.Equal(t, testcase.expected, err.Error(), testcase.source)
+		}
+	}
+}
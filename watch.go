@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How long to wait for further changes to a package before linting it.
+	watchDebounce = 300 * time.Millisecond
+	// How often the polling watcher checks for changes.
+	watchPollInterval = time.Second
+)
+
+// fileWatcher reports directories in which Go source files or subdirectories
+// have changed.
+type fileWatcher interface {
+	Changes() <-chan string
+	// Err returns the error that stopped the watcher, once Changes is closed.
+	Err() error
+	Close() error
+}
+
+// pollingWatcher detects changes by periodically comparing the names, sizes and
+// modification times of the Go files in each directory, and the names of its
+// subdirectories.
+type pollingWatcher struct {
+	dirs    []string
+	changes chan string
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func newPollingWatcher(dirs []string, interval time.Duration) *pollingWatcher {
+	w := &pollingWatcher{
+		dirs:    dirs,
+		changes: make(chan string, len(dirs)),
+		stop:    make(chan struct{}),
+	}
+	signatures := map[string]string{}
+	for _, dir := range dirs {
+		signatures[dir] = dirSignature(dir)
+	}
+	go w.run(interval, signatures)
+	return w
+}
+
+func (w *pollingWatcher) run(interval time.Duration, signatures map[string]string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(w.changes)
+	for {
+		select {
+		case <-w.stop:
+			return
+
+		case <-ticker.C:
+			for _, dir := range w.dirs {
+				signature := dirSignature(dir)
+				if signature == signatures[dir] {
+					continue
+				}
+				signatures[dir] = signature
+				select {
+				case w.changes <- dir:
+				case <-w.stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *pollingWatcher) Changes() <-chan string {
+	return w.changes
+}
+
+func (w *pollingWatcher) Err() error {
+	return nil
+}
+
+func (w *pollingWatcher) Close() error {
+	w.once.Do(func() { close(w.stop) })
+	return nil
+}
+
+func dirSignature(dir string) string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	signature := ""
+	for _, info := range infos {
+		switch {
+		case info.IsDir():
+			signature += info.Name() + "/\n"
+		case strings.HasSuffix(info.Name(), ".go"):
+			signature += fmt.Sprintf("%s:%d:%d\n", info.Name(), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return signature
+}
+
+// watchRun is a single run of the linters over the packages in dirs, or over
+// every package if dirs is nil.
+type watchRun struct {
+	dirs      []string
+	cancel    chan struct{}
+	cancelled bool
+	issues    []*Issue
+}
+
+// covers returns true if the run lints any of dirs.
+func (r *watchRun) covers(dirs *stringSet) bool {
+	for _, dir := range r.dirs {
+		if dirs.contains(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch lints the packages in args, then re-lints packages whenever their
+// files change, printing the full list of issues after every run. Packages
+// that change while a run is in progress are linted together once it
+// completes, so that at most --concurrency linters run at a time. Packages
+// are resolved from args again after every change, so that packages in new
+// directories are linted too. It only returns if watching for changes fails.
+// nolint: gocyclo
+func watch(linters map[string]*Linter, args []string, exclude, include *regexp.Regexp) error {
+	paths, dirs := resolvePackagesAndDirs(args, config.Skip)
+	watcher := newFileWatcher(dirs)
+	defer func() { _ = watcher.Close() }()
+
+	results := make(chan *watchRun)
+	ready := make(chan string)
+	pending := map[string]*time.Timer{}
+	issues := map[string][]*Issue{}
+	// Packages removed since the initial run, whose issues it must not report.
+	removed := map[string]bool{}
+	// Packages to lint once the current run completes.
+	queued := newStringSet()
+	var active *watchRun
+
+	// cancelIfCovers cancels the current run if it lints any of dirs, as its
+	// results would be out of date, and queues its packages to be linted again.
+	cancelIfCovers := func(dirs *stringSet) {
+		if active == nil || active.cancelled || !active.covers(dirs) {
+			return
+		}
+		close(active.cancel)
+		active.cancelled = true
+		for _, dir := range active.dirs {
+			queued.add(dir)
+		}
+	}
+
+	lint := func(run *watchRun, lintPaths []string) {
+		active = run
+		go func() {
+			out, errch, _ := runLinters(linters, lintPaths, config.Concurrency, exclude, include, run.cancel)
+			for issue := range out {
+				run.issues = append(run.issues, issue)
+			}
+			for err := range errch {
+				select {
+				case <-run.cancel:
+				default:
+					warning("%s", err)
+				}
+			}
+			results <- run
+		}()
+	}
+
+	// lintQueued lints the queued packages that still exist in a single run,
+	// unless a run is already in progress.
+	lintQueued := func() {
+		if active != nil {
+			return
+		}
+		run := &watchRun{cancel: make(chan struct{})}
+		for _, path := range paths {
+			if queued.contains(path) {
+				run.dirs = append(run.dirs, path)
+			}
+		}
+		queued = newStringSet()
+		if len(run.dirs) == 0 {
+			return
+		}
+		debug("watch: linting %s", strings.Join(run.dirs, " "))
+		lint(run, run.dirs)
+	}
+
+	lint(&watchRun{cancel: make(chan struct{})}, paths)
+	for {
+		select {
+		case dir, ok := <-watcher.Changes():
+			if !ok {
+				if err := watcher.Err(); err != nil {
+					return fmt.Errorf("stopped watching for changes: %s", err)
+				}
+				return fmt.Errorf("stopped watching for changes")
+			}
+			if timer, ok := pending[dir]; ok {
+				timer.Stop()
+			}
+			pending[dir] = time.AfterFunc(watchDebounce, func() { ready <- dir })
+
+		case dir := <-ready:
+			delete(pending, dir)
+			resolved, resolvedDirs := resolvePackagesAndDirs(args, config.Skip)
+			if strings.Join(resolvedDirs, "\x00") != strings.Join(dirs, "\x00") {
+				debug("watch: directories changed, watching %s", strings.Join(resolvedDirs, " "))
+				_ = watcher.Close()
+				watcher = newFileWatcher(resolvedDirs)
+				dirs = resolvedDirs
+			}
+			previous := newStringSet(paths...)
+			current := newStringSet(resolved...)
+			paths = resolved
+			changed := newStringSet()
+			removedAny := false
+			for _, path := range previous.asSlice() {
+				if !current.contains(path) {
+					removed[filepath.Clean(path)] = true
+					delete(issues, filepath.Clean(path))
+					changed.add(path)
+					removedAny = true
+				}
+			}
+			for _, path := range resolved {
+				if path == dir || !previous.contains(path) {
+					delete(removed, filepath.Clean(path))
+					queued.add(path)
+					changed.add(path)
+				}
+			}
+			cancelIfCovers(changed)
+			lintQueued()
+			if removedAny {
+				printWatchIssues(issues)
+			}
+
+		case run := <-results:
+			active = nil
+			if !run.cancelled {
+				linted := map[string]bool{}
+				for _, dir := range run.dirs {
+					linted[filepath.Clean(dir)] = true
+					delete(issues, filepath.Clean(dir))
+				}
+				for _, issue := range run.issues {
+					dir := filepath.Clean(filepath.Dir(issue.Path))
+					if run.dirs == nil && !removed[dir] || linted[dir] {
+						issues[dir] = append(issues[dir], issue)
+					}
+				}
+				printWatchIssues(issues)
+			}
+			lintQueued()
+		}
+	}
+}
+
+func printWatchIssues(issues map[string][]*Issue) {
+	sorted := &sortedIssues{order: []string{"path", "line", "column", "linter"}}
+	for _, dirIssues := range issues {
+		for _, issue := range dirIssues {
+			if config.Errors && issue.Severity != Error {
+				continue
+			}
+			sorted.issues = append(sorted.issues, issue)
+		}
+	}
+	sort.Sort(sorted)
+	fmt.Printf("--- %s: %d issues\n", time.Now().Format("15:04:05"), len(sorted.issues))
+	for _, issue := range sorted.issues {
+		fmt.Println(issue.String())
+	}
+}
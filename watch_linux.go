@@ -0,0 +1,107 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotifyWatcher reports changes using the Linux inotify API.
+type inotifyWatcher struct {
+	file    *os.File
+	dirs    map[int32]string
+	changes chan string
+	stop    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// newFileWatcher watches dirs using inotify, falling back to polling if
+// inotify is unavailable (eg. because the watch limit has been reached).
+func newFileWatcher(dirs []string) fileWatcher {
+	w, err := newInotifyWatcher(dirs)
+	if err != nil {
+		debug("watch: inotify unavailable, polling for changes: %s", err)
+		return newPollingWatcher(dirs, watchPollInterval)
+	}
+	return w
+}
+
+func newInotifyWatcher(dirs []string) (*inotifyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotifyWatcher{
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		dirs:    map[int32]string{},
+		changes: make(chan string, len(dirs)),
+		stop:    make(chan struct{}),
+	}
+	for _, dir := range dirs {
+		wd, err := syscall.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			_ = w.file.Close()
+			return nil, err
+		}
+		w.dirs[int32(wd)] = dir
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) run() {
+	defer close(w.changes)
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.stop:
+			default:
+				w.err = err
+			}
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset])) // nolint: gas
+			start := offset + syscall.SizeofInotifyEvent
+			offset = start + int(event.Len)
+			name := strings.TrimRight(string(buf[start:offset]), "\x00")
+			isDir := event.Mask&syscall.IN_ISDIR != 0
+			if dir, ok := w.dirs[event.Wd]; ok && (isDir || strings.HasSuffix(name, ".go")) {
+				// Nothing receives changes once the watcher is closed.
+				select {
+				case w.changes <- dir:
+				case <-w.stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *inotifyWatcher) Changes() <-chan string {
+	return w.changes
+}
+
+func (w *inotifyWatcher) Err() error {
+	return w.err
+}
+
+func (w *inotifyWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.stop)
+		err = w.file.Close()
+	})
+	return err
+}
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInotifyWatcherReportsReadError(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "foo")
+	watcher, err := newInotifyWatcher([]string{"foo"})
+	require.NoError(t, err)
+	defer watcher.Close() // nolint: errcheck
+
+	// Closing the file without closing the watcher is reported as an error.
+	require.NoError(t, watcher.file.Close())
+	select {
+	case _, ok := <-watcher.Changes():
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the watcher to stop")
+	}
+	assert.Error(t, watcher.Err())
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+func newFileWatcher(dirs []string) fileWatcher {
+	return newPollingWatcher(dirs, watchPollInterval)
+}
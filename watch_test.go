@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFileWatcher(t *testing.T, newWatcher func(dirs []string) fileWatcher) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "foo")
+	mkDir(t, tmpdir, "bar")
+	watcher := newWatcher([]string{"foo", "bar"})
+	defer watcher.Close() // nolint: errcheck
+
+	err := ioutil.WriteFile(filepath.Join("bar", "file.go"), []byte("package bar\n"), 0644)
+	require.NoError(t, err)
+	expectWatcherChange(t, watcher, "bar")
+
+	// Creating a directory is reported, so that new packages can be found.
+	require.NoError(t, os.Mkdir(filepath.Join("foo", "new"), 0755))
+	expectWatcherChange(t, watcher, "foo")
+}
+
+func expectWatcherChange(t *testing.T, watcher fileWatcher, expected string) {
+	select {
+	case dir := <-watcher.Changes():
+		assert.Equal(t, expected, dir)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+}
+
+func TestPollingWatcher(t *testing.T) {
+	testFileWatcher(t, func(dirs []string) fileWatcher {
+		return newPollingWatcher(dirs, 10*time.Millisecond)
+	})
+}
+
+func TestFileWatcher(t *testing.T) {
+	testFileWatcher(t, newFileWatcher)
+}
+
+func TestFileWatcherCloseWithUnreadChanges(t *testing.T) {
+	tmpdir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mkDir(t, tmpdir, "foo")
+	goroutines := runtime.NumGoroutine()
+	watcher := newFileWatcher([]string{"foo"})
+	// More changes than the watcher buffers, none of which are read.
+	for i := 0; i < 10; i++ {
+		err := ioutil.WriteFile(filepath.Join("foo", fmt.Sprintf("file%d.go", i)), []byte("package foo\n"), 0644)
+		require.NoError(t, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, watcher.Close())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+}